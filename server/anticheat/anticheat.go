@@ -0,0 +1,164 @@
+package anticheat
+
+import (
+	"math"
+	"time"
+)
+
+// Results are checked for typing no person could do. An impossible speed
+// voids the result outright; anything else that looks automated flags it
+// for someone to review. Keys sent one at a time are timed by when the
+// server got them, so network jitter can bunch up a few keystrokes and the
+// timing checks leave room for that.
+const (
+	// Faster than any recorded typist over a whole tweet
+	MaxSpeed = 250.0
+
+	// Timing checks need this many keystrokes to mean anything
+	MinKeystrokes = 20
+
+	// BurstKeys keystrokes within BurstWindow is over 1000 WPM. A stall on
+	// the network can cause one, so it takes MaxBursts of them to be flagged.
+	BurstKeys   = 12
+	BurstWindow = 140 * time.Millisecond
+	MaxBursts   = 2
+
+	// People never type at an even pace; scripts do. This is the lowest
+	// allowed ratio of the spread of intervals between keys to their mean.
+	MinVariation = 0.15
+
+	// Intervals shorter than FastInterval are over 480 WPM. People hit a
+	// few; a run made mostly of them is automated.
+	FastInterval = 25 * time.Millisecond
+	MaxFastShare = 0.5
+
+	// A keystroke carrying this many graphemes was pasted, as was a run
+	// where this share of keys arrived at the same moment as the one before
+	PasteGraphemes = 4
+	MaxPasteShare  = 0.25
+)
+
+// Flags
+const (
+	ImpossibleSpeed = "impossibleSpeed"
+	Bursts          = "bursts"
+	UniformCadence  = "uniformCadence"
+	FastIntervals   = "fastIntervals"
+	Paste           = "paste"
+)
+
+// Keystroke is one key applied to a player's text, Offset into the round
+type Keystroke struct {
+	Offset    time.Duration
+	Graphemes int
+}
+
+type Verdict struct {
+	Flags []string
+	// The result doesn't count at all
+	Void bool
+}
+
+// Clean is whether nothing was found
+func (v Verdict) Clean() bool {
+	return len(v.Flags) == 0
+}
+
+// Analyze checks a player's keystrokes and the net speed they were scored
+func Analyze(keys []Keystroke, speed float64) Verdict {
+	var verdict Verdict
+
+	if speed > MaxSpeed {
+		verdict.Flags = append(verdict.Flags, ImpossibleSpeed)
+		verdict.Void = true
+	}
+
+	for i := range keys {
+		if keys[i].Graphemes >= PasteGraphemes {
+			verdict.Flags = append(verdict.Flags, Paste)
+			break
+		}
+	}
+
+	if len(keys) < MinKeystrokes {
+		return verdict
+	}
+
+	intervals := make([]time.Duration, len(keys) - 1)
+	for i := 1; i < len(keys); i++ {
+		intervals[i - 1] = keys[i].Offset - keys[i - 1].Offset
+	}
+
+	if countBursts(keys) > MaxBursts {
+		verdict.Flags = append(verdict.Flags, Bursts)
+	}
+
+	if variation(intervals) < MinVariation {
+		verdict.Flags = append(verdict.Flags, UniformCadence)
+	}
+
+	if share(intervals, func(d time.Duration) bool { return d < FastInterval }) > MaxFastShare {
+		verdict.Flags = append(verdict.Flags, FastIntervals)
+	}
+
+	if !has(verdict.Flags, Paste) && share(intervals, func(d time.Duration) bool { return d == 0 }) > MaxPasteShare {
+		verdict.Flags = append(verdict.Flags, Paste)
+	}
+
+	return verdict
+}
+
+// countBursts counts separate runs of BurstKeys keystrokes inside
+// BurstWindow
+func countBursts(keys []Keystroke) int {
+	count := 0
+	for i := 0; i + BurstKeys <= len(keys); {
+		if keys[i + BurstKeys - 1].Offset - keys[i].Offset < BurstWindow {
+			count += 1
+			i += BurstKeys
+		} else {
+			i += 1
+		}
+	}
+	return count
+}
+
+// variation is the coefficient of variation of the intervals
+func variation(intervals []time.Duration) float64 {
+	mean := 0.0
+	for i := range intervals {
+		mean += float64(intervals[i])
+	}
+	mean /= float64(len(intervals))
+	if mean == 0 {
+		return 0
+	}
+
+	variance := 0.0
+	for i := range intervals {
+		diff := float64(intervals[i]) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(intervals))
+
+	return math.Sqrt(variance) / mean
+}
+
+func share(intervals []time.Duration, match func(time.Duration) bool) float64 {
+	count := 0
+	for i := range intervals {
+		if match(intervals[i]) {
+			count += 1
+		}
+	}
+	return float64(count) / float64(len(intervals))
+}
+
+func has(flags []string, flag string) bool {
+	for i := range flags {
+		if flags[i] == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package anticheat
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// typed turns intervals between keys into keystrokes of one grapheme each
+func typed(intervals []time.Duration) []Keystroke {
+	keys := []Keystroke{{Offset: 300 * time.Millisecond, Graphemes: 1}}
+	for _, interval := range intervals {
+		keys = append(keys, Keystroke{Offset: keys[len(keys) - 1].Offset + interval, Graphemes: 1})
+	}
+	return keys
+}
+
+// human is someone typing about 70 WPM, unevenly
+func human(count int) []time.Duration {
+	random := rand.New(rand.NewSource(int64(count)))
+	intervals := make([]time.Duration, count)
+	for i := range intervals {
+		intervals[i] = time.Duration(100 + random.Intn(240)) * time.Millisecond
+	}
+	return intervals
+}
+
+// every repeats a pattern of intervals
+func every(count int, pattern ...time.Duration) []time.Duration {
+	intervals := make([]time.Duration, count)
+	for i := range intervals {
+		intervals[i] = pattern[i % len(pattern)]
+	}
+	return intervals
+}
+
+// withBursts is a human run with bursts of BurstKeys keystrokes 8ms apart
+func withBursts(bursts int) []time.Duration {
+	intervals := human(200)
+	for b := 0; b < bursts; b++ {
+		start := 20 + b * 50
+		for i := start; i < start + BurstKeys - 1; i++ {
+			intervals[i] = 8 * time.Millisecond
+		}
+	}
+	return intervals
+}
+
+func pasted(keys []Keystroke, graphemes int) []Keystroke {
+	keys[len(keys) / 2].Graphemes = graphemes
+	return keys
+}
+
+func TestAnalyze(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name  string
+		keys  []Keystroke
+		speed float64
+		flags []string
+		void  bool
+	}{
+		{"human", typed(human(150)), 70, nil, false},
+		{"nothing typed", nil, 0, nil, false},
+		{"too few keys to time", typed(every(MinKeystrokes - 2, 150 * ms)), 70, nil, false},
+
+		{"fastest typist", typed(human(150)), MaxSpeed, nil, false},
+		{"impossible speed", typed(human(150)), MaxSpeed + 1, []string{ImpossibleSpeed}, true},
+
+		{"pasted chunk", pasted(typed(human(150)), PasteGraphemes), 70, []string{Paste}, false},
+		{"pasted into a short run", pasted(typed(human(5)), PasteGraphemes), 70, []string{Paste}, false},
+		{"ellipsis", pasted(typed(human(150)), PasteGraphemes - 1), 70, nil, false},
+
+		{"network stalls", typed(withBursts(MaxBursts)), 70, nil, false},
+		{"bursts", typed(withBursts(MaxBursts + 1)), 70, []string{Bursts}, false},
+
+		{"even cadence", typed(every(150, 150 * ms)), 80, []string{UniformCadence}, false},
+		{"nearly even cadence", typed(every(150, 145 * ms, 150 * ms, 155 * ms)), 80, []string{UniformCadence}, false},
+
+		{"half fast", typed(every(150, 10 * ms, 300 * ms)), 90, nil, false},
+		{"mostly fast", typed(every(150, 10 * ms, 10 * ms, 400 * ms)), 90, []string{FastIntervals}, false},
+
+		{"a quarter at once", typed(every(148, 0, 200 * ms, 150 * ms, 250 * ms)), 90, nil, false},
+		{"a third at once", typed(every(150, 0, 200 * ms, 250 * ms)), 90, []string{Paste}, false},
+
+		// Keys that all arrive together have no spread at all, and are
+		// both fast and pasted
+		{"all at once", typed(every(30, 0)), 200, []string{UniformCadence, FastIntervals, Paste}, false},
+	}
+
+	for _, test := range tests {
+		verdict := Analyze(test.keys, test.speed)
+		if !reflect.DeepEqual(verdict.Flags, test.flags) || verdict.Void != test.void {
+			t.Errorf("%s: flags %v void %v, want %v void %v", test.name, verdict.Flags, verdict.Void, test.flags, test.void)
+		}
+		if verdict.Clean() != (len(test.flags) == 0) {
+			t.Errorf("%s: clean is %v with %v", test.name, verdict.Clean(), verdict.Flags)
+		}
+	}
+}
@@ -3,33 +3,61 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	dotenv "github.com/joho/godotenv"
 )
 
 type Config struct {
 	HTTPServerAddress string
+	// Signs session tokens. Changing it signs everyone out, which is how to
+	// end every session at once, e.g. if the key leaks.
 	AccessTokenKey    string
+	// "memory" keeps everything in process memory instead of redis
+	Store             string
 	RedisAddress      string
 	RedisPassword     string
+	// Ids of the players who can review flagged results, without the
+	// Player: prefix
+	Reviewers         []string
+	// ID tokens are checked against this issuer, client id and key set,
+	// which is a URL or the path of a local file
+	OIDCIssuer        string
+	OIDCAudience      string
+	OIDCJWKS          string
 }
 
 var Conf *Config
 
 func init() {
+	// Fall back to the process environment when there is no .env file
 	if err := dotenv.Load(".env"); err != nil {
-		log.Fatal("failed to load environment variables")
+		log.Println("no .env file found, using environment variables")
 	}
 
 	http_addr := os.Getenv("HTTP_SERVER_ADDRESS")
 	access_token_key := os.Getenv("TOKEN_KEY")
+	store := os.Getenv("STORE")
 	redis_address := os.Getenv("REDIS_ADDR")
 	redis_password := os.Getenv("REDIS_PASS")
+	reviewers := os.Getenv("REVIEWERS")
+	oidc_issuer := os.Getenv("OIDC_ISSUER")
+	oidc_audience := os.Getenv("OIDC_AUDIENCE")
+	oidc_jwks := os.Getenv("OIDC_JWKS")
 
 	var config Config
 	config.HTTPServerAddress = http_addr
 	config.AccessTokenKey = access_token_key
+	config.Store = store
 	config.RedisAddress = redis_address
 	config.RedisPassword = redis_password
+	config.OIDCIssuer = oidc_issuer
+	config.OIDCAudience = oidc_audience
+	config.OIDCJWKS = oidc_jwks
+	for _, reviewer := range strings.Split(reviewers, ",") {
+		if reviewer = strings.TrimSpace(reviewer); reviewer != "" {
+			config.Reviewers = append(config.Reviewers, reviewer)
+		}
+	}
 	Conf = &config
-}
\ No newline at end of file
+}
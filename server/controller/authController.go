@@ -6,37 +6,96 @@ import (
 	"net/http"
 	"server/config"
 	"server/database"
+	"server/oidc"
+	"strings"
+	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v4"
 	uuid "github.com/satori/go.uuid"
 )
 
+// Sign in takes an OpenID Connect ID token. Players are identified by the
+// token's subject; its email, name and picture are only profile data.
+// main sets the verifier up; without one sign in is disabled.
+var IdTokens *oidc.Verifier
+
 type SigninBody struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Picture string `json:"picture"`
+	IdToken string `json:"idToken"`
+}
+
+var errAccountConflict = errors.New("account belongs to someone else")
+
+// signinPlayer returns the player a verified identity signs in as, creating
+// them the first time
+func signinPlayer(claims *oidc.Claims) (string, error) {
+	if player_id, err := database.DB.GetSubjectPlayer(claims.Subject); err != database.ErrNotFound {
+		return player_id, err
+	}
+
+	// Players from before ID tokens were checked are keyed by email. The
+	// first verified sign in with the email takes the account over.
+	legacy_id := database.PlayerPrefix + claims.Email
+	if claims.Email != "" && claims.Verified() && database.DB.PlayerExists(legacy_id) {
+		err := database.DB.LinkSubject(claims.Subject, legacy_id)
+		if err == nil {
+			return legacy_id, nil
+		} else if err != database.ErrLinked {
+			return "", err
+		}
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+
+	// The player is created and linked to the subject together, so a
+	// failed sign in can't leave the subject linked to nobody
+	player_id, err := database.DB.CreatePlayer(claims.Subject, name, claims.Email, claims.Picture)
+	if err == database.ErrLinked {
+		// Another sign in with the subject may have just created it.
+		// Otherwise an old account is keyed by an email that happens to be
+		// the subject.
+		if linked, lookup_err := database.DB.GetSubjectPlayer(claims.Subject); lookup_err == nil {
+			return linked, nil
+		}
+		return "", errAccountConflict
+	} else if err != nil {
+		return "", err
+	}
+
+	database.DB.IncrementAccountsCreated()
+	return player_id, nil
 }
 
 func SigninHandler(w http.ResponseWriter, r *http.Request) {
-	var user_info SigninBody
+	if IdTokens == nil {
+		http.Error(w, "sign in isn't configured", http.StatusServiceUnavailable)
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&user_info); err != nil {
+	var body SigninBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Unable to parse json", http.StatusBadRequest)
 		return
 	}
 
-	key := database.PlayerPrefix + user_info.Email
+	claims, err := IdTokens.Verify(body.IdToken)
+	if err != nil {
+		http.Error(w, "invalid id token: " + err.Error(), http.StatusUnauthorized)
+		return
+	}
 
-	if err := database.RedisClient.Get(database.Ctx, key).Err(); err == redis.Nil {
-		if _, err := database.CreatePlayerRedis(user_info.Name, user_info.Email, user_info.Picture); err != nil {
-			http.Error(w, "Unable to create user", http.StatusBadRequest)
-			return
-		}
-		database.IncrementAccountsCreated()
+	player_id, err := signinPlayer(claims)
+	if err == errAccountConflict {
+		http.Error(w, "account belongs to someone else", http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, "Unable to create user", http.StatusBadRequest)
+		return
 	}
 
-	if token, err := CreateJWT(key); err != nil {
+	if token, err := CreateJWT(player_id); err != nil {
 		http.Error(w, "failed to create jwt", http.StatusBadRequest)
 	} else {
 		w.Header().Set("Content-Type", "application/json")
@@ -45,13 +104,20 @@ func SigninHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 
+// Sessions last this long before the player has to sign in again
+const TokenLifetime = 30 * 24 * time.Hour
+
 type JWTClaims struct {
 	Id string
 	jwt.RegisteredClaims
 }
 
 func CreateJWT(id string) (string, error) {
-	claims := JWTClaims{id, jwt.RegisteredClaims{}}
+	now := time.Now()
+	claims := JWTClaims{id, jwt.RegisteredClaims{
+		IssuedAt: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(TokenLifetime)),
+	}}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	key := config.Conf.AccessTokenKey
 
@@ -75,7 +141,8 @@ func ParseJWT(token_str string) (*JWTClaims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+	// Tokens from before sessions expired never do, so they are refused
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && claims.ExpiresAt != nil && claims.IssuedAt != nil {
 		return claims, nil
 	} else {
 		return nil, errors.New("invalid claims")
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"server/config"
+	"server/database"
+	"server/oidc"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	uuid "github.com/satori/go.uuid"
+)
+
+func identity(subject string) *oidc.Claims {
+	return &oidc.Claims{
+		Name: "signin",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+	}
+}
+
+func TestSigninPlayer(t *testing.T) {
+	subject := "signin-" + uuid.NewV4().String()
+	player_id, err := signinPlayer(identity(subject))
+	if err != nil || player_id != database.PlayerPrefix + subject {
+		t.Fatalf("signed in as %q: %v", player_id, err)
+	}
+	if again, err := signinPlayer(identity(subject)); err != nil || again != player_id {
+		t.Errorf("signed in again as %q: %v", again, err)
+	}
+
+	// An old account whose key is the subject belongs to someone else, and
+	// the subject is left unlinked
+	owner := "signin-" + uuid.NewV4().String()
+	taken := "signin-" + uuid.NewV4().String()
+	if err := database.DB.LinkSubject(owner, database.PlayerPrefix + taken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signinPlayer(identity(taken)); err != errAccountConflict {
+		t.Errorf("signed in to someone else's account: %v", err)
+	}
+	if linked, err := database.DB.GetSubjectPlayer(taken); err != database.ErrNotFound {
+		t.Errorf("subject was linked to %q", linked)
+	}
+}
+
+func TestJWT(t *testing.T) {
+	key := config.Conf.AccessTokenKey
+	config.Conf.AccessTokenKey = "test-key"
+	defer func() { config.Conf.AccessTokenKey = key }()
+
+	token, err := CreateJWT("Player:jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseJWT(token)
+	if err != nil || claims.Id != "Player:jwt" {
+		t.Fatalf("parsed %+v: %v", claims, err)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != TokenLifetime {
+		t.Errorf("token lasts %v, want %v", lifetime, TokenLifetime)
+	}
+
+	sign := func(claims JWTClaims, key string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", sign(JWTClaims{"Player:jwt", jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(now.Add(-TokenLifetime - time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(-time.Hour)),
+		}}, "test-key")},
+		{"from before sessions expired", sign(JWTClaims{"Player:jwt", jwt.RegisteredClaims{}}, "test-key")},
+		{"no issue time", sign(JWTClaims{"Player:jwt", jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		}}, "test-key")},
+		{"old key", sign(JWTClaims{"Player:jwt", jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		}}, "old-key")},
+		{"garbage", "not a token"},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseJWT(test.token); err == nil {
+			t.Errorf("%s token was accepted", test.name)
+		}
+		if player_id, player_type := PlayerOrGuest(test.token); player_type != "guest" {
+			t.Errorf("%s token signed in as %s", test.name, player_id)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"server/anticheat"
+	"server/database"
+	"server/text"
+	"strings"
+	"time"
+)
+
+// Every player's keystrokes are checked when a round is scored. Voided
+// results count for nothing; flagged ones stay out of the player's stats,
+// ratings and personal bests. A reviewer approving one adds it to their
+// stats and personal best, but not their rating: the game's other players
+// have been rated since, so it can't be worked out again.
+
+// checkPlayer runs the cheat checks over what the player typed this round
+func (g *Game) checkPlayer(player *Player) anticheat.Verdict {
+	var keys []anticheat.Keystroke
+
+	for _, key := range player.Status.Keystrokes {
+		if key.Mark == database.KeyRejected {
+			continue
+		}
+
+		graphemes := len(text.Keys(key.Key, g.TextOptions))
+		if key.Key == BackspaceKey {
+			graphemes = 1
+		}
+
+		// Modifier keys don't type anything
+		if graphemes == 0 {
+			continue
+		}
+
+		keys = append(keys, anticheat.Keystroke{Offset: key.Offset, Graphemes: graphemes})
+	}
+
+	return anticheat.Analyze(keys, player.Status.Result.NetWPM)
+}
+
+// recordResult adds a player's result to their stats, or holds it back for
+// review if it was flagged
+func (g *Game) recordResult(player *Player, result database.GameResult) {
+	verdict := player.Status.Verdict
+	if verdict.Void {
+		return
+	}
+
+	if verdict.Clean() {
+		database.DB.PlayerPlayedGame(player.Id, result)
+		return
+	}
+
+	// Guests have no stats to hold back
+	if isRegistered(player.Id) {
+		var best *database.Run
+		if run, ok := g.personalBest(player, track(player, g.typedTweet(player))); ok {
+			best = &run
+		}
+
+		database.DB.FlagResult(database.FlaggedResult{
+			PlayerId: player.Id,
+			Name: player.Name,
+			GameId: strings.Split(g.Id, ":")[1],
+			Rematch: g.Rematches,
+			Round: g.Round,
+			Flags: verdict.Flags,
+			Result: result,
+			Run: best,
+			Time: time.Now(),
+		})
+	}
+}
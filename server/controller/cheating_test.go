@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"server/anticheat"
+	"server/config"
+	"server/database"
+	"server/text"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	uuid "github.com/satori/go.uuid"
+)
+
+// finishedPlayer is a registered player who typed the game's whole tweet
+func finishedPlayer(t *testing.T, g *Game) *Player {
+	player_id, err := database.DB.CreatePlayer("review-" + uuid.NewV4().String(), "review", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player := NewPlayer(player_id, "review", false, nil, Keyboards[0])
+	player.Status.State = Typing
+	g.Players[player.Id] = player
+	for i, grapheme := range g.TweetGraphemes {
+		g.applyKey(player, grapheme, time.Duration(i + 1) * 150 * time.Millisecond)
+	}
+	player.Status.Result.NetWPM = 80
+	player.Status.Placement = 1
+	return player
+}
+
+func reviewGame() *Game {
+	g := &Game{Id: "Game:review", Type: PublicGame, TextOptions: text.DefaultOptions, Players: make(map[string]*Player)}
+	g.Settings.TypingMode = RealTyping
+	g.setTweet("TweetPrefix:review-" + uuid.NewV4().String(), "a quick test", 3, "", "", nil)
+	return g
+}
+
+// flagged returns the player's result from the review queue
+func flagged(t *testing.T, player_id string) database.FlaggedResult {
+	results, _, err := database.DB.GetFlaggedResults(0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range results {
+		if results[i].PlayerId == player_id {
+			return results[i]
+		}
+	}
+	t.Fatalf("%s wasn't flagged", player_id)
+	return database.FlaggedResult{}
+}
+
+func TestApprovedResult(t *testing.T) {
+	reviewers := config.Conf.Reviewers
+	config.Conf.Reviewers = []string{"reviewer"}
+	defer func() { config.Conf.Reviewers = reviewers }()
+
+	for _, approve := range []bool{true, false} {
+		g := reviewGame()
+		player := finishedPlayer(t, g)
+		player.Status.Verdict = anticheat.Verdict{Flags: []string{"burst"}}
+		g.recordResult(player, database.GameResult{Speed: 80, Points: 10, Won: true})
+		g.saveReplay("review")
+
+		if _, err := database.DB.GetPersonalBest(g.TweetId, RealTyping, player.Id); err == nil {
+			t.Fatal("a flagged run was kept as a personal best")
+		}
+		result := flagged(t, player.Id)
+		if result.Run == nil || result.Run.Speed != 80 || !result.Run.Track.Finished {
+			t.Fatalf("flagged result has run %+v", result.Run)
+		}
+
+		body := []byte(`{"approve":false}`)
+		if approve {
+			body = []byte(`{"approve":true}`)
+		}
+		r := playerRequest("POST", "/reviews/" + result.Id, database.PlayerPrefix + "reviewer", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		ResolveReviewHandler(w, mux.SetURLVars(r, map[string]string{"id": result.Id}))
+		if w.Code != http.StatusOK {
+			t.Fatalf("resolving: %d %s", w.Code, w.Body)
+		}
+
+		_, err := database.DB.GetPersonalBest(g.TweetId, RealTyping, player.Id)
+		stats := database.DB.GetPlayerStats(player.Id)
+		if approve && (err != nil || stats["MatchesPlayed"] != 1) {
+			t.Errorf("approved: personal best %v, stats %v", err, stats)
+		} else if !approve && (err == nil || stats["MatchesPlayed"] != 0) {
+			t.Errorf("rejected: personal best %v, stats %v", err, stats)
+		}
+	}
+}
+
+func TestVoidedHistory(t *testing.T) {
+	g := reviewGame()
+	player := finishedPlayer(t, g)
+	player.Status.Verdict = anticheat.Verdict{Flags: []string{"speed"}, Void: true}
+	g.recordMatch(nil)
+
+	matches, _, err := database.DB.GetPlayerMatches(player.Id, 0, 1)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("no match history: %v", err)
+	}
+	participant := matches[0].Participants[0]
+	if !participant.Voided || participant.Speed != 0 || participant.RawSpeed != 0 {
+		t.Errorf("voided result is kept as %+v", participant)
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"server/protocol"
+
+	"github.com/gorilla/websocket"
+)
+
+const ClientSendBuffer = 256
+
+// Client is a player's websocket connection. Messages are queued on send and
+// written by writePump, so a slow connection never blocks the game loop.
+type Client struct {
+	Conn     *websocket.Conn
+	PlayerId string
+	Keyboard Keyboard
+	send     chan []byte
+	closed   bool
+}
+
+func NewClient(conn *websocket.Conn, player_id string, keyboard Keyboard) *Client {
+	return &Client{
+		Conn: conn,
+		PlayerId: player_id,
+		Keyboard: keyboard,
+		send: make(chan []byte, ClientSendBuffer),
+	}
+}
+
+// writePump writes queued messages until the client is closed or the
+// connection fails, then closes the connection
+func (c *Client) writePump() {
+	for message := range c.send {
+		if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			break
+		}
+	}
+	c.Conn.Close()
+}
+
+// write queues a message for the client. A client that has fallen too far
+// behind is dropped rather than stalling the game.
+func (c *Client) write(message []byte) {
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.send <- message:
+	default:
+		c.drop()
+	}
+}
+
+func (c *Client) sendMessage(message protocol.ServerMessage) {
+	if message_json, err := protocol.Encode(message); err == nil {
+		c.write(message_json)
+	}
+}
+
+// close stops the write pump. Only the game loop owning the client calls it.
+func (c *Client) close() {
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// drop closes the client and its connection straight away, so the reader
+// stops too and nothing more is written to it
+func (c *Client) drop() {
+	c.close()
+	c.Conn.Close()
+}
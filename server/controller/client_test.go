@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// A client that stops reading is dropped once its buffer fills, and nothing
+// is queued or written for it after that
+func TestSlowClientDropped(t *testing.T) {
+	client := NewClient(testConn(), "Player:slow", Keyboards[0])
+
+	for i := 0; i < ClientSendBuffer; i++ {
+		client.write([]byte("{}"))
+	}
+	if client.closed {
+		t.Fatal("dropped before the buffer was full")
+	}
+
+	client.write([]byte("{}"))
+	if !client.closed {
+		t.Fatal("a full buffer didn't drop the client")
+	}
+
+	// The game keeps broadcasting to it until it sees the disconnect
+	client.write([]byte("{}"))
+	client.close()
+
+	queued := 0
+	for range client.send {
+		queued += 1
+	}
+	if queued != ClientSendBuffer {
+		t.Errorf("%d messages queued, want %d", queued, ClientSendBuffer)
+	}
+	if err := client.Conn.WriteMessage(websocket.TextMessage, []byte("{}")); err == nil {
+		t.Error("the connection is still open")
+	}
+}
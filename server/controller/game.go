@@ -1,19 +1,17 @@
 package controller
 
 import (
-	"encoding/json"
+	"math"
+	"server/anticheat"
 	"server/database"
+	"server/protocol"
+	"server/scoring"
+	"server/text"
 	"sort"
+	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
-type Response struct {
-	Action string      `json:"action"` 
-	Data   interface{} `json:"data"`
-}
-
 const (
 	Typing               = "Typing"
 	Guessing             = "Guessing"
@@ -22,14 +20,29 @@ const (
 	Countdown            = "Countdown"
 	Started              = "Started"
 	Finished             = "Finished"
-	GuessPointsBonus     = 10
+	BetweenRounds        = "BetweenRounds"
+	RoundBreak           = 10
+	RematchWindow        = 120
 	MaxPlayersInGame     = 6
 	RoundTimeLimit       = 45
+	GuessTimeLimit       = 15
+	DisconnectGracePeriod = 30
+	KeystrokeClockTolerance = 500 * time.Millisecond
+	MaxKeystrokeDelay    = 10 * time.Second
+	MinKeystrokeInterval = 10 * time.Millisecond
 	PublicGameCountdown  = 20
 	PrivateGameCountdown = 5
 	PrivateGameTimeLimit = 15
 	PublicGame           = "PublicGame"
 	PrivateGame          = "PrivateGame"
+	GhostGame            = "GhostGame"
+	TickInterval         = 100 * time.Millisecond
+	Connected            = "Connected"
+	Disconnected         = "Disconnected"
+	StrictTyping         = "StrictTyping"
+	RealTyping           = "RealTyping"
+	BackspaceKey         = "Backspace"
+	MaxErrorBuffer       = 20
 )
 
 type PlayerGameStatus struct {
@@ -42,6 +55,21 @@ type PlayerGameStatus struct {
 	TypingStartTime  time.Time
 	IncorrectAnswers int 
 	CurrentLetterIdx int
+	LastKeyOffset    time.Duration
+	KeysTyped        int
+	Progress         []time.Duration
+	Result           scoring.Result
+	Guess            string
+	GuessedAuthor    bool
+	Keystrokes       []RecordedKey
+	Verdict          anticheat.Verdict
+
+	// Graphemes typed after a mistake in real typing mode. They have to be
+	// deleted before CurrentLetterIdx can move on.
+	Buffer            []string
+	CorrectedErrors   int
+	UncorrectedErrors int
+	WordErrors        map[int]int
 }
 
 func NewPlayerGameStatus() *PlayerGameStatus {
@@ -53,6 +81,7 @@ func NewPlayerGameStatus() *PlayerGameStatus {
 		CorrectAnswers: 0, 
 		IncorrectAnswers: 0, 
 		CurrentLetterIdx: 0,
+		WordErrors: make(map[int]int),
 	}
 }
 
@@ -60,396 +89,625 @@ type Player struct {
 	Id      string
 	Name    string
 	Creator bool
-	Conn    *websocket.Conn
+	Client  *Client
 	Status  PlayerGameStatus
 	Keyboard Keyboard
+	PublicId       string
+	SessionToken   string
+	Connection     string
+	DisconnectTime time.Time
+	MatchPoints    float64
+	RoundsWon      int
+	JoinTime       time.Time
+	Ready          bool
+	// Where a ghost's run came from; empty for real players
+	Ghost          string
+
+	// What the other players last saw of this player
+	lastSent protocol.PlayerInfo
 }
 
 func NewPlayer(
 	id string, 
 	name string, 
 	creator bool, 
-	conn *websocket.Conn, 
+	client *Client, 
 	keyboard Keyboard,
 ) *Player {
 	return &Player{
 		Id: id, 
 		Name: name, 
-		Conn: conn, 
+		Client: client, 
 		Creator: creator,
 		Keyboard: keyboard,
+		Connection: Connected,
+		PublicId: randomHex(4),
+		SessionToken: newSessionToken(),
+		JoinTime: time.Now(),
 		Status: *NewPlayerGameStatus(),
 	}
 }
 
 type Game struct {
 	Id                 string
-	CreateTime         time.Time
 	Type               string
 	Winner             string
 	TimeLimit          int
 	MaxPlayers         int
 	State              string
+	TweetId            string
 	Tweet              string
+	TweetGraphemes     []string
 	TweetWordCnt       int
+	TextOptions        text.Options
+	Settings           protocol.GameSettings
+	ScoringRule        scoring.ScoringRule
 	Author             string
 	AuthorHandle       string
 	AuthorChoices      []string
 	CountdownStartTime time.Time
+	RoundStartTime     time.Time
+	TypingDeadline     time.Time
+	GuessingDeadline   time.Time
+	LastTickTime       time.Time
+	Round              int
+	// Rematches started so far, which keep their replays apart
+	Rematches          int
+	NextRoundTime      time.Time
+	RematchDeadline    time.Time
+	PlayedTweets       map[string]bool
+	Locked             bool
+	Kicked             map[string]bool
+	ReadyCheck         bool
+	Ranked             bool
 	Players 	          map[string]*Player
+	Spectators         map[string]*Spectator
+	Ghosts             map[string]*Ghost
+
+	// Only the game loop touches the fields above; everything else talks
+	// to the game through these channels
+	actions chan playerAction
+	calls   chan func()
+	done    chan struct{}
+	removed bool
+
+	// When the game was created or last restarted by a rematch. The sweep
+	// for stale games reads it, so it is guarded by GamesMutex instead.
+	CreateTime time.Time
 }
 
-func NewGame(player_id string, game_type string) (*Game, error) {
-	tweet_id, tweet, tweet_word_count, author, author_handle, choices := generateTweet()
-
-	if game_id, err := database.CreateGameRedis(
-			Lobby, tweet_id, player_id, MaxPlayersInGame, RoundTimeLimit); err != nil {
+func NewGame(player_id string, game_type string, settings protocol.GameSettings) (*Game, error) {
+	game := Game{
+		State: Lobby, 
+		Type: game_type,
+		CreateTime: time.Now(),
+		Round: 1,
+		PlayedTweets: make(map[string]bool),
+		Kicked: make(map[string]bool),
+		Players: make(map[string]*Player), 
+		Spectators: make(map[string]*Spectator),
+		Ghosts: make(map[string]*Ghost),
+		actions: make(chan playerAction),
+		calls: make(chan func()),
+		done: make(chan struct{}),
+	}
+	game.applySettings(settings)
+
+	if game_id, err := database.DB.CreateGame(
+			Lobby, game.TweetId, player_id, game.MaxPlayers, game.TimeLimit); err != nil {
 		return nil, err
 	} else {
-		game := Game{
-			Id: game_id, 
-			State: Lobby, 
-			Tweet: tweet,
-			Author: author,
-			Type: game_type,
-			CreateTime: time.Now(),
-			AuthorChoices: choices,
-			TimeLimit: RoundTimeLimit,
-			AuthorHandle: author_handle,
-			MaxPlayers: MaxPlayersInGame,
-			TweetWordCnt: tweet_word_count,
-			Players: make(map[string]*Player), 
-		}
+		game.Id = game_id
+		go game.run()
 		return &game, nil
 	}
 }
 
-func (g *Game) broadcastMessage(message []byte) {
-	for i := range g.Players {
-		g.Players[i].Conn.WriteMessage(
-			websocket.TextMessage, message,
-		)
+// newTweet picks a tweet matching the game's settings, trying not to repeat
+// one from an earlier round. Players type the normalized tweet one grapheme
+// at a time.
+func (g *Game) newTweet() {
+	tweet_id, tweet, tweet_word_count, author, author_handle, choices := generateTweet(g.Settings)
+	for attempt := 0; attempt < 10 && g.PlayedTweets[tweet_id]; attempt++ {
+		tweet_id, tweet, tweet_word_count, author, author_handle, choices = generateTweet(g.Settings)
 	}
+	g.setTweet(tweet_id, tweet, tweet_word_count, author, author_handle, choices)
 }
 
-func (g *Game) sendError(conn *websocket.Conn, player_id string, message string) {
-	var result Response 
-	result.Action = "error"
-	result.Data = message
+func (g *Game) setTweet(
+	tweet_id string, 
+	tweet string, 
+	tweet_word_count int, 
+	author string, 
+	author_handle string, 
+	choices []string,
+) {
+	graphemes := text.Prepare(tweet, g.TextOptions)
+
+	g.TweetId = tweet_id
+	g.Tweet = strings.Join(graphemes, "")
+	g.TweetGraphemes = graphemes
+	g.TweetWordCnt = tweet_word_count
+	g.Author = author
+	g.AuthorHandle = author_handle
+	g.AuthorChoices = choices
+}
 
-	result_json, err := json.Marshal(result)	
+func (g *Game) broadcastMessage(message protocol.ServerMessage) {
+	message_json, err := protocol.Encode(message)
 	if err != nil {
 		return
 	}
 
-	conn.WriteMessage(websocket.TextMessage, result_json)
+	for i := range g.Players {
+		g.Players[i].Client.write(message_json)
+	}
+
+	for i := range g.Spectators {
+		g.Spectators[i].Client.write(message_json)
+	}
+}
+
+func (g *Game) sendError(client *Client, code protocol.ErrorCode, message string) {
+	client.sendMessage(protocol.NewError(code, message))
 }
 
 func (g *Game) registerPlayer(
-	message map[string]*json.RawMessage, 
-	conn *websocket.Conn, 
+	data *protocol.RegisterPlayerAction, 
+	client *Client, 
 	player_id string, 
 	keyboard Keyboard,
 ) {
-	// Prevent too many players from joining one game
-	if len(g.Players) == g.MaxPlayers {
-		g.sendError(conn, player_id, "Too many players")
+	if g.Kicked[player_id] {
+		g.sendError(client, protocol.Kicked, "You were removed from the game by the host")
 		return
 	}
 
-	// For private games, users can only join through lobby
-	if g.Type == PrivateGame && g.State != Lobby {
-		g.sendError(conn, player_id, "Game has already started")
+	if g.Locked {
+		g.sendError(client, protocol.RoomLocked, "The host has locked the room")
 		return
 	}
 
-	// For public games, users can only join during lobby or countdown
-	if g.Type == PublicGame && g.State != Countdown && g.State != Lobby {
-		g.sendError(conn, player_id, "Game has already started")
+	if g.Ranked && !isRegistered(player_id) {
+		g.sendError(client, protocol.SignInRequired, "Sign in to play ranked games")
+		return
 	}
 
-	type Data struct {
-		Name string `json:"name"`
+	// Prevent too many players from joining one game
+	if len(g.Players) == g.MaxPlayers {
+		g.sendError(client, protocol.GameFull, "Too many players")
+		return
 	}
 
-	var data Data
-	err := json.Unmarshal(*message["data"], &data)
-	if err != nil {
+	// For private and ghost games, users can only join through lobby
+	if (g.Type == PrivateGame || g.Type == GhostGame) && g.State != Lobby {
+		g.sendError(client, protocol.GameAlreadyStarted, "Game has already started")
 		return
 	}
 
-	var result Response
-	result.Data = g.Type
-	result.Action = "sendGameType"
-		
-	if result_json, err := json.Marshal(result); err != nil {
+	// For public games, users can only join during lobby or countdown
+	if g.Type == PublicGame && g.State != Countdown && g.State != Lobby {
+		g.sendError(client, protocol.GameAlreadyStarted, "Game has already started")
 		return
-	} else {
-		conn.WriteMessage(websocket.TextMessage, result_json)
 	}
 
-	g.Players[player_id] = NewPlayer(player_id, data.Name, len(g.Players) == 0, conn, keyboard)
-	database.AddPlayerToGameRedis(g.Id, player_id)
+	client.sendMessage(protocol.SendGameTypeMessage(g.Type))
+	client.sendMessage((*protocol.SendSettingsMessage)(&g.Settings))
+
+	// A spectator joining the game stops watching
+	delete(g.Spectators, player_id)
+
+	g.Players[player_id] = NewPlayer(player_id, data.Name, len(g.Players) == 0, client, keyboard)
+	database.DB.AddPlayerToGame(g.Id, player_id)
+	g.sendSession(g.Players[player_id])
 }
 
 func (g *Game) unregisterPlayer(player_id string) {
-	database.RemovePlayerFromGameRedis(g.Id, player_id)
+	database.DB.RemovePlayerFromGame(g.Id, player_id)
 	delete(g.Players, player_id)
 }
 
+// sendActivePlayers sends every player and spectator the full roster. It is
+// used when players join or leave; progress is sent by sendPlayerUpdates.
 func (g *Game) sendActivePlayers(player_id string) {
-	type PlayerInfo struct {
-		Name             string  `json:"name"`
-		KeyboardLink     string  `json:"keyboardLink"`
-		Speed            float64 `json:"speed"`
-		Points           float64 `json:"points"`
-		State            string  `json:"state"`
-		IsUser           bool    `json:"isUser"`
-		Placement        int     `json:"placement"`
-		IsCreator        bool    `json:"isCreator"`
-		CorrectAnswers   int     `json:"correctAnswers"`
-		IncorrectAnswers int     `json:"incorrectAnswers"` 
-		CurrentLetterIdx int     `json:"currentLetterIdx"`
-	}
-	
+	var roster protocol.SendActivePlayersMessage
+
 	for i := range g.Players {
-		var result Response
-		var player_info []PlayerInfo
+		g.Players[i].lastSent = g.playerInfo(g.Players[i])
+		roster = append(roster, g.Players[i].lastSent)
+	}
+
+	var ghosts []protocol.PlayerInfo
+	for i := range g.Ghosts {
+		g.Ghosts[i].Player.lastSent = g.playerInfo(g.Ghosts[i].Player)
+		ghosts = append(ghosts, g.Ghosts[i].Player.lastSent)
+	}
+	roster = append(roster, ghosts...)
+
+	for i := range g.Spectators {
+		g.Spectators[i].Client.sendMessage(roster)
+	}
+
+	for i := range g.Players {
+		var player_info protocol.SendActivePlayersMessage
 		
 		for j := range g.Players {
-			player_info = append(
-				player_info, 
-				PlayerInfo{
-					Name: g.Players[j].Name, 
-					IsCreator: g.Players[j].Creator,
-					Speed: g.Players[j].Status.Speed,
-					State: g.Players[j].Status.State,
-					Points: g.Players[j].Status.Points,
-					Placement: g.Players[j].Status.Placement,
-					IsUser: g.Players[i].Id == g.Players[j].Id,
-					CorrectAnswers: g.Players[j].Status.CorrectAnswers,
-					KeyboardLink: g.Players[j].Keyboard.ClientImageLink,
-					IncorrectAnswers: g.Players[j].Status.IncorrectAnswers,
-					CurrentLetterIdx: g.Players[j].Status.CurrentLetterIdx,
-				})
+			info := g.Players[j].lastSent
+			info.IsUser = g.Players[i].Id == g.Players[j].Id
+			player_info = append(player_info, info)
 		}
+		player_info = append(player_info, ghosts...)
 
-		result.Action = "sendActivePlayers"
-		result.Data = player_info
-		
-		if result_json, err := json.Marshal(result); err != nil {
-			return
-		} else {
-			g.Players[i].Conn.WriteMessage(websocket.TextMessage, result_json)
-		}
+		g.Players[i].Client.sendMessage(player_info)
 	}
+
+	g.sendSpectators()
 }
 
-func (g *Game) startCountdown(conn *websocket.Conn, player_id string)  { 
-	// Countdown for private games can only be started from the lobby
-	if g.Type == PrivateGame && g.State != Lobby {
-		g.sendError(conn, player_id, "Countdown can only be started from lobby")
+func (g *Game) startCountdown(client *Client, player_id string)  { 
+	// Countdown for private games can only be started from the lobby or
+	// between rounds
+	if g.Type == PrivateGame && g.State != Lobby && g.State != BetweenRounds {
+		g.sendError(client, protocol.CountdownNotAllowed, "Countdown can only be started from lobby")
 		return
 	}
 
-	var timer int
-	if g.Type == PublicGame { 
-		timer = PublicGameCountdown 
-	} else { 
-		timer = PrivateGameCountdown 
-	}
+	timer := g.countdownTimer()
 
 	if g.State != Countdown {
 		// Countdown hasn't started -> start countdown
-		g.State = Countdown
-		g.CountdownStartTime = time.Now()
-
-		var result Response = Response{
-			Action: "startCountdown", 
-			Data: struct{ State string `json:"state"`; Clock int `json:"clock"` }{ 
-				State: Countdown, Clock: timer,
-			}}
-
-		if result_json, err := json.Marshal(result); err != nil { return } else {
-			g.broadcastMessage(result_json)
-			database.UpdateGameStatusRedis(g.Id, Countdown)
-		}
-
-		go func() {
-			time.Sleep(time.Duration(timer) * time.Second)
-			g.startGame(conn, player_id)
-		}()
+		g.beginCountdown()
 
 	} else {
 		// Countdown has already started -> send time remaining
-		var result Response = Response{
-			Action: "startCountdown",
-			Data: struct{ State string `json:"state"`; Clock int `json:"clock"` }{
-				State: Countdown, Clock: timer - int(time.Since(g.CountdownStartTime).Seconds()),
-			}}
-		
-		if result_json, err := json.Marshal(result); err != nil { return } else {
-			conn.WriteMessage(websocket.TextMessage, result_json)
-		}
+		client.sendMessage(&protocol.StartCountdownMessage{
+			State: Countdown, Clock: timer - int(time.Since(g.CountdownStartTime).Seconds()),
+		})
 	}
 }
 
-func (g *Game) startGame(conn *websocket.Conn, player_id string) {	
+func (g *Game) beginCountdown() {
+	g.State = Countdown
+	g.CountdownStartTime = time.Now()
+
+	g.broadcastMessage(&protocol.StartCountdownMessage{State: Countdown, Clock: g.countdownTimer()})
+	database.DB.UpdateGameStatus(g.Id, Countdown)
+}
+
+func (g *Game) countdownTimer() int {
+	return g.Settings.Countdown
+}
+
+func (g *Game) startGame() {	
 	if g.State != Countdown {
-		g.sendError(conn, player_id, "Not in countdown mode")
 		return
 	}
 
-	var result Response 
-	result.Action = "startGame"
-	tmp := make(map[string]interface{})
-	tmp["state"] = Started
-	tmp["tweet"] = g.Tweet
-	tmp["authorChoices"] = g.AuthorChoices
-	result.Data = tmp
+	g.State = Started
+	g.PlayedTweets[g.TweetId] = true
+	g.matchmakingPool().Close(g.Id)
+	g.broadcastMessage(&protocol.StartGameMessage{
+		State: Started,
+		Tweet: g.Tweet,
+		Graphemes: g.TweetGraphemes,
+		TypingMode: g.Settings.TypingMode,
+		ScoringRule: g.Settings.ScoringRule,
+		AuthorChoices: g.authorChoices(),
+	})
+	database.DB.UpdateGameStatus(g.Id, Started)
 
-	if result_json, err := json.Marshal(result); err == nil {
-		g.State = Started
-		g.broadcastMessage(result_json)
-		database.UpdateGameStatusRedis(g.Id, Started)
+	g.RoundStartTime = time.Now()
+	g.TypingDeadline = g.RoundStartTime.Add(time.Duration(g.TimeLimit) * time.Second)
+	for _, player := range g.racers() {
+		player.Status.TypingStartTime = g.RoundStartTime
 	}
+}
+
+// endTyping moves anyone still typing on to guessing and starts the guessing
+// deadline. It runs once everyone has finished typing or the time limit is up.
+// Games without guessing finish here.
+func (g *Game) endTyping(now time.Time) {
+	g.finishGhosts()
 
 	for i := range g.Players {
-		g.Players[i].Status.TypingStartTime = time.Now()
+		if g.Players[i].Status.State == Typing {
+			g.Players[i].Status.UncorrectedErrors = g.bufferErrors(g.Players[i])
+			g.finishTyping(g.Players[i], now)
+		}
 	}
 
-	go func() {
-		time.Sleep(time.Duration(g.TimeLimit) * time.Second)
-		for i := range g.Players {
-			if g.Players[i].Status.State == Typing {
-				g.Players[i].Status.TypingEndTime = time.Now()
-				g.Players[i].Status.State = Guessing
-			}
-		}
-		g.sendActivePlayers(player_id)
-	}()
+	if !g.Settings.GuessingEnabled {
+		g.startFinish("")
+		return
+	}
+
+	g.GuessingDeadline = now.Add(time.Duration(g.Settings.GuessTimeLimit) * time.Second)
 }
 
-func (g *Game) playerMove(message map[string]*json.RawMessage, conn *websocket.Conn, player_id string) {
-	type Data struct {
-		Key string `json:"key"`
+// finishTyping moves a player on from typing, straight to completed when
+// the game has no guessing
+func (g *Game) finishTyping(player *Player, at time.Time) {
+	player.Status.TypingEndTime = at
+	player.Status.State = Guessing
+
+	if !g.Settings.GuessingEnabled {
+		player.Status.State = Completed
 	}
+}
 
-	if (g.Players[player_id].Status.State != Typing) {
-		return
+// endGuessing completes everyone who hasn't guessed yet with no guess
+// points and finishes the game
+func (g *Game) endGuessing() {
+	for i := range g.Players {
+		if g.Players[i].Status.State != Completed {
+			g.Players[i].Status.State = Completed
+		}
 	}
 
-	var data Data
-	if err := json.Unmarshal(*message["data"], &data); err != nil {
+	g.startFinish("")
+}
+
+// phase returns the timed phase the game is in and when it ends
+func (g *Game) phase() (string, time.Time) {
+	switch {
+	case g.State == Countdown:
+		return Countdown, g.CountdownStartTime.Add(time.Duration(g.countdownTimer()) * time.Second)
+	case g.State == Started && g.GuessingDeadline.IsZero():
+		return Typing, g.TypingDeadline
+	case g.State == Started:
+		return Guessing, g.GuessingDeadline
+	case g.State == BetweenRounds:
+		return BetweenRounds, g.NextRoundTime
+	case g.State == Finished:
+		return Finished, g.RematchDeadline
+	}
+	return g.State, time.Time{}
+}
+
+func (g *Game) sendTick(now time.Time) {
+	phase, deadline := g.phase()
+	if deadline.IsZero() {
 		return
 	}
 
-	if data.Key == string(g.Tweet[g.Players[player_id].Status.CurrentLetterIdx]) {
-		g.Players[player_id].Status.CurrentLetterIdx += 1		
-		g.Players[player_id].Status.CorrectAnswers += 1
-	} else if g.Players[player_id].Status.CurrentLetterIdx != len(g.Tweet) {
-		g.Players[player_id].Status.IncorrectAnswers += 1
+	g.LastTickTime = now
+	g.broadcastMessage(&protocol.TickMessage{
+		State: g.State, Phase: phase, Clock: secondsLeft(deadline, now),
+	})
+}
+
+func secondsLeft(deadline time.Time, now time.Time) int {
+	clock := int(math.Ceil(deadline.Sub(now).Seconds()))
+	if clock < 0 { clock = 0 }
+	return clock
+}
+
+func (g *Game) countTypingPlayers() int {
+	var count int = 0
+	for i := range g.Players {
+		if g.Players[i].Status.State == Typing {
+			count += 1
+		}
+	}
+	return count
+}
+
+func (g *Game) playerMove(data *protocol.PlayerMoveAction, client *Client, player_id string) {
+	player, ok := g.Players[player_id]
+	if !ok || player.Status.State != Typing {
+		return
 	}
 
-	if g.Players[player_id].Status.CurrentLetterIdx == len(g.Tweet) {
-		g.Players[player_id].Status.TypingEndTime = time.Now()
-		g.Players[player_id].Status.State = Guessing
+	// Single keys are timed by when the server received them
+	offset := time.Since(g.RoundStartTime)
+	if offset < player.Status.LastKeyOffset {
+		offset = player.Status.LastKeyOffset
 	}
 
-	g.sendActivePlayers(player_id)
+	g.applyKey(player, data.Key, offset)
 }
 
-func (g *Game) playerGuess(message map[string]*json.RawMessage, conn *websocket.Conn, player_id string) {
-	type Data struct {
-		Guess string `json:"guess"`
+// applyKey checks a key the player typed offset into the round.
+// CurrentLetterIdx counts graphemes, not bytes.
+func (g *Game) applyKey(player *Player, key string, offset time.Duration) {
+	player.Status.LastKeyOffset = offset
+	player.Status.KeysTyped += 1
+	previous_idx := player.Status.CurrentLetterIdx
+	previous_errors := player.Status.IncorrectAnswers
+
+	if g.Settings.TypingMode == RealTyping {
+		g.applyRealKey(player, key)
+	} else {
+		g.applyStrictKey(player, key)
 	}
 
-	if g.Players[player_id].Status.State != Guessing {
-		return
+	if player.Status.IncorrectAnswers > previous_errors {
+		recordKey(player, key, offset, database.KeyError)
+	} else {
+		recordKey(player, key, offset, database.KeyCorrect)
+	}
+
+	// Remember when each grapheme was typed for the per second speeds
+	for i := previous_idx; i < player.Status.CurrentLetterIdx; i++ {
+		player.Status.Progress = append(player.Status.Progress, offset)
+	}
+
+	if player.Status.CurrentLetterIdx == len(g.TweetGraphemes) {
+		g.finishTyping(player, g.RoundStartTime.Add(offset))
 	}
+}
 
-	var data Data
-	if err := json.Unmarshal(*message["data"], &data); err != nil {
+func (g *Game) playerGuess(data *protocol.PlayerGuessAction, client *Client, player_id string) {
+	if player, ok := g.Players[player_id]; !ok || player.Status.State != Guessing {
 		return
 	}
 
+	// Points are worked out by the game's scoring rule once everyone is done
+	g.Players[player_id].Status.Guess = data.Guess
 	if data.Guess == g.Author {
-		g.Players[player_id].Status.Points += GuessPointsBonus
+		g.Players[player_id].Status.GuessedAuthor = true
 	}
 
 	g.Players[player_id].Status.State = Completed
-	g.sendActivePlayers(player_id)
 
 	if g.countCompletedPlayers() == len(g.Players) {
 		g.startFinish(player_id)
 	}
 }
 
+// startFinish ends the round. Matches with rounds left go on to a break
+// before the next one; otherwise the game is over.
 func (g *Game) startFinish(player_id string) {
 	if g.State != Started {
 		return
 	}
 
-	g.State = Finished
-	database.UpdateGameStatusRedis(g.Id, Finished)
-	
-	var player_points []map[string]interface{}
+	results := g.scoreRound()
+	g.updateRatings(results)
+	g.recordMatch(results)
 
-	for i := range g.Players {
-		startTime := g.Players[i].Status.TypingStartTime
-		endTime := g.Players[i].Status.TypingEndTime
-		words_per_minute := (float64(g.Players[i].Status.CurrentLetterIdx) / 5) / endTime.Sub(startTime).Minutes()
+	if g.Round < g.Settings.Rounds {
+		g.startBreak(results)
+		return
+	}
 
-		g.Players[i].Status.Points += words_per_minute
-		g.Players[i].Status.Speed = words_per_minute
+	g.State = Finished
+	database.DB.UpdateGameStatus(g.Id, Finished)
 
-		val := make(map[string]interface{})
-		val["id"] = g.Players[i].Id
-		val["points"] = g.Players[i].Status.Points
-		player_points = append(player_points, val)
+	scoreboard := g.scoreboard()
+	if len(scoreboard) > 0 {
+		g.Winner = scoreboard[0].Id
 	}
 
-	sort.Slice(player_points, func(i, j int) bool { 
-		return player_points[i]["points"].(float64) > player_points[j]["points"].(float64)
+	g.broadcastMessage(&protocol.StartFinishMessage{
+		State: Finished,
+		Author: g.Author,
+		AuthorHandle: g.AuthorHandle,
+		Round: g.Round,
+		Rounds: g.Settings.Rounds,
+		Results: results,
+		Scoreboard: matchStandings(scoreboard),
 	})
 
-	for i := range player_points {
-		player_id := player_points[i]["id"].(string)
-		g.Players[player_id].Status.Placement = (i + 1) 
+	g.sendActivePlayers(player_id)
+
+	// Private rooms stay open for a while so the host can start a rematch
+	if g.Type == PrivateGame {
+		g.RematchDeadline = time.Now().Add(RematchWindow * time.Second)
+		return
+	}
+	g.removeGame()
+}
 
-		correct := float64(g.Players[player_id].Status.CorrectAnswers)
-		incorrect := float64(g.Players[player_id].Status.IncorrectAnswers)
+// scoreRound works out everyone's speed, points and placement for the round
+// and records it in their stats
+func (g *Game) scoreRound() []protocol.PlayerResult {
+	var players []*Player
+	var standings []scoring.Standing
+	var results []protocol.PlayerResult
+
+	for _, player := range g.racers() {
+		status := &player.Status
+		typing_time := status.TypingEndTime.Sub(status.TypingStartTime)
+
+		status.Result = scoring.Score(scoring.Input{
+			Entries: status.CurrentLetterIdx + len(status.Buffer),
+			Correct: status.CorrectAnswers,
+			Incorrect: status.IncorrectAnswers,
+			UncorrectedErrors: status.UncorrectedErrors,
+			Duration: typing_time,
+			Progress: status.Progress,
+		})
+		status.Speed = status.Result.NetWPM
+
+		// Ghosts replay runs that were already checked
+		if player.Ghost == "" {
+			status.Verdict = g.checkPlayer(player)
+		}
 
-		database.PlayerPlayedGameRedis(
-			player_points[i]["id"].(string), 
-			g.Players[player_id].Status.Speed, 
-			correct / (correct + incorrect),
-			g.Players[player_id].Status.Placement == 1, 
-			g.Players[player_id].Status.Points + g.Players[player_id].Status.Speed, 
-		)
+		players = append(players, player)
+		standings = append(standings, scoring.Standing{
+			Result: status.Result,
+			GuessedAuthor: status.GuessedAuthor,
+			Progress: status.CurrentLetterIdx,
+			Finished: status.CurrentLetterIdx == len(g.TweetGraphemes),
+			TypingTime: typing_time,
+		})
+
+		results = append(results, protocol.PlayerResult{
+			Id: player.PublicId,
+			GrossWPM: status.Result.GrossWPM,
+			NetWPM: status.Result.NetWPM,
+			Accuracy: status.Result.Accuracy,
+			Samples: status.Result.Samples,
+			Consistency: status.Result.Consistency,
+			CorrectedErrors: status.CorrectedErrors,
+			UncorrectedErrors: status.UncorrectedErrors,
+			WordErrors: g.wordErrors(player),
+			Voided: status.Verdict.Void,
+			UnderReview: !status.Verdict.Void && !status.Verdict.Clean(),
+		})
+	}
+
+	// Voided results place last
+	points := g.ScoringRule.Points(standings)
+	for i := range players {
+		if players[i].Status.Verdict.Void {
+			points[i] = 0
+		}
+		players[i].Status.Points = points[i]
 	}
 
-	g.Winner = player_points[0]["id"].(string)
+	sort.SliceStable(players, func(i, j int) bool { 
+		return players[i].Status.Points > players[j].Status.Points
+	})
 
-	var result Response
-	result.Action = "startFinish"
-	tmp := make(map[string]interface{})
-	tmp["state"] = Finished
-	tmp["author"] = g.Author
-	tmp["authorHandle"] = g.AuthorHandle
-	result.Data = tmp
-	
-	if result_json, err := json.Marshal(result); err == nil {
-		g.broadcastMessage(result_json)
-	} 
+	for i := range players {
+		status := &players[i].Status
+		status.Placement = (i + 1) 
 
-	g.sendActivePlayers(player_id)
-	g.removeGame()
+		players[i].MatchPoints += status.Points
+		if status.Placement == 1 {
+			players[i].RoundsWon += 1
+		}
+
+		// Racing ghosts is practice and doesn't count towards stats
+		if g.Type == GhostGame {
+			continue
+		}
+
+		g.recordResult(players[i], database.GameResult{
+			Speed: status.Result.NetWPM,
+			RawSpeed: status.Result.GrossWPM,
+			Accuracy: status.Result.Accuracy,
+			Consistency: status.Result.Consistency,
+			Won: status.Placement == 1,
+			Points: status.Points,
+		})
+	}
+
+	return results
 }
 
 func (g *Game) removeGame() {
-	database.DeleteGameRedis(g.Id)
-	delete(Games, g.Id)
+	if g.removed {
+		return
+	}
+
+	database.DB.DeleteGame(g.Id)
+	deleteGame(g.Id)
+	g.matchmakingPool().Close(g.Id)
+
+	for i := range g.Players {
+		g.Players[i].Client.close()
+	}
+
+	for i := range g.Spectators {
+		g.Spectators[i].Client.close()
+	}
+
+	g.removed = true
+	close(g.done)
 }
 
 func (g *Game) countCompletedPlayers() int {
@@ -461,11 +719,3 @@ func (g *Game) countCompletedPlayers() int {
 	}
 	return count
 }
-
-func clearEmptyGames() {
-	for i := range Games {
-		if time.Since(Games[i].CreateTime) > time.Hour {
-			delete(Games, i)
-		}
-	}
-}
\ No newline at end of file
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"server/database"
+	"server/matchmaking"
+	"server/protocol"
 	"strings"
 
 	"github.com/gorilla/websocket"
@@ -15,17 +18,24 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin:     func(r *http.Request) bool { return true },
 }
 
-var Games = make(map[string]*Game)
-
 func JoinGameHandler(w http.ResponseWriter, r *http.Request) {
 	game_id := database.GamePrefix + r.URL.Query().Get("id")
+	spectate := r.URL.Query().Get("spectate") == "true"
 
-	if _, ok := Games[game_id]; !ok {
+	game, ok := getGame(game_id)
+	if !ok {
 		http.Error(w, "invalid code", 400)
 		return
 	}
 
-	if Games[game_id].State != Lobby || len(Games[game_id].Players) == Games[game_id].MaxPlayers {
+	// Spectators can watch a game in any state
+	var joinable bool
+	game.call(func() {
+		joinable = spectate ||
+			(game.State == Lobby && len(game.Players) < game.MaxPlayers && !game.Locked)
+	})
+
+	if !joinable {
 		http.Error(w, "can't join this game", 400)
 		return
 	}
@@ -35,40 +45,71 @@ func JoinGameHandler(w http.ResponseWriter, r *http.Request) {
 
 func JoinRandomGameHandler(w http.ResponseWriter, r *http.Request) {
 	player_id := r.Context().Value("player").(string)
-	game_id, err := database.DequeueGame()
+	joinMatchmaking(w, r, Matchmaker, player_id, playerRating(player_id))
+}
+
+// JoinRankedGameHandler matches signed in players on their competitive
+// rating
+func JoinRankedGameHandler(w http.ResponseWriter, r *http.Request) {
+	player_id := r.Context().Value("player").(string)
+	if !isRegistered(player_id) {
+		http.Error(w, "sign in to play ranked games", http.StatusUnauthorized)
+		return
+	}
+
+	joinMatchmaking(w, r, RankedMatchmaker, player_id, competitiveRating(player_id).Rating)
+}
+
+// joinMatchmaking waits for the pool to find the player a game with players
+// of a similar rating and sends them its code
+func joinMatchmaking(
+	w http.ResponseWriter, 
+	r *http.Request, 
+	pool *matchmaking.Pool, 
+	player_id string, 
+	rating float64,
+) {
 	// Check if there are some invalid games that haven't been cleared from memory
 	clearEmptyGames()
 
-	if err != nil {
-		// If there is no game the user can join -> create a new game and add to queue
-		if game, err := NewGame(player_id, PublicGame); err != nil {
+	ticket, found := findGame(pool, player_id, rating)
+
+	select {
+	case game_id := <-found:
+		if game_id == "" {
 			http.Error(w, "failed to create game", http.StatusBadRequest)
 			return
-		} else {
-			database.IncrementGamesCreated()
-			database.Queue(game.Id)
-			Games[game.Id] = game
-			shortened_game_id := strings.Split(game.Id, ":")[1]
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(shortened_game_id)
-			return
 		}
-	} else {
-		// If there is a game the user can join -> return that game
+
 		shortened_game_id := strings.Split(game_id, ":")[1]
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(shortened_game_id)
+
+	case <-r.Context().Done():
+		stopFinding(pool, ticket)
 	}
 }
 
 func CreateGameHandler(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; any settings left out keep their defaults
+	settings := DefaultSettings(PrivateGame)
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil && err != io.EOF {
+		http.Error(w, "invalid settings", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateSettings(settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	player_id := r.Context().Value("player").(string)
 
-	if game, err := NewGame(player_id, PrivateGame); err != nil {
+	if game, err := NewGame(player_id, PrivateGame, settings); err != nil {
 		http.Error(w, "failed to create game", http.StatusBadRequest)
 	} else {
-		database.IncrementGamesCreated()
-		Games[game.Id] = game
+		database.DB.IncrementGamesCreated()
+		addGame(game)
 		shortened_game_id := strings.Split(game.Id, ":")[1]
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(shortened_game_id)
@@ -80,6 +121,11 @@ func GetAllKeyboardsHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(Keyboards)
 }
 
+func GetProtocolSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(protocol.Schema())
+}
+
 func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	game_id := database.GamePrefix + r.URL.Query().Get("id")
 	token := r.URL.Query().Get("token")
@@ -88,97 +134,58 @@ func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	player_keyboard := Keyboards[0]
 
 	if player_type == "player" {
-		keyboard_id := database.GetPlayerSelectedKeyboard(player_id)
+		keyboard_id := database.DB.GetPlayerSelectedKeyboard(player_id)
 		player_keyboard = Keyboards[keyboard_id]
 	} 
 
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		http.Error(w, "failed to create connection", 400)
-		return
-	}
-
-	game, ok := Games[game_id]
+	game, ok := getGame(game_id)
 	if !ok {
 		http.Error(w, "game doesn't exist", 400)
 		return
 	}
 
-	var message map[string]*json.RawMessage
-
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
 	defer conn.Close()
 
+	client := NewClient(conn, player_id, player_keyboard)
+	go client.writePump()
+
 	for {
-		if err = conn.ReadJSON(&message); err != nil {
-			if websocket.IsUnexpectedCloseError(err) {
-				game.unregisterPlayer(player_id)
-				game.sendActivePlayers(player_id)
-				if len(game.Players) == 0 {
-					game.removeGame()
-				}
-				break
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			// The game loop closes the client once it has seen the disconnect
+			if !game.send(playerAction{client: client, message: &disconnectMessage{}}) {
+				client.close()
 			}
+			break
 		}
 
-		var action string
-		if err := json.Unmarshal(*message["action"], &action); err != nil {
-			continue
-		}
-
-		if action == "ping" {
-			var result Response
-			result.Action = "pong"
-			if result_json, err := json.Marshal(result); err == nil {
-				conn.WriteMessage(websocket.TextMessage, result_json)
-			} 
-		}
-
-		switch game.State {
-		case Lobby:
-			if action == "registerPlayer" {
-				game.registerPlayer(message, conn, player_id, player_keyboard)
-				game.sendActivePlayers(player_id)
-
-				// Start public games on first player join
-				if game.Type == PublicGame {
-					game.startCountdown(conn, player_id)
-				}
-			}
-
-			if action == "startCountdown" {
-				game.sendActivePlayers(player_id)
-				game.startCountdown(conn, player_id)
-			}
-
-		case Countdown:
-			if action == "registerPlayer" && game.Type == PublicGame {
-				game.registerPlayer(message, conn, player_id, player_keyboard)
-				game.startCountdown(conn, player_id)
-				game.sendActivePlayers(player_id)
-			}
-
-		case Started:
-			if action == "playerMove" {
-				game.playerMove(message, conn, player_id)
-			}
-
-			if action == "playerGuess" {
-				game.playerGuess(message, conn, player_id)
-			}
-		}
+		message, decode_err := protocol.Decode(raw)
+		game.send(playerAction{client: client, message: message, err: decode_err})
 	}
 }
 
 func GetPlayerStatsHandler(w http.ResponseWriter, r *http.Request) {
 	player_id := r.Context().Value("player").(string)
-	result := database.GetPlayerStatsRedis(player_id)
+	result := database.DB.GetPlayerStats(player_id)
+
+	if isRegistered(player_id) {
+		current := competitiveRating(player_id)
+		result["Rating"] = current.Rating
+		result["RatingDeviation"] = current.Deviation
+		result["RatingHistory"] = database.DB.GetRatingHistory(player_id, database.RatingHistoryLimit)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)	
 }
 
 func GetPlayerKeyboardsHandler(w http.ResponseWriter, r *http.Request) {
 	player_id := r.Context().Value("player").(string)
-	result := database.GetPlayerKeyboardsRedis(player_id)
+	result := database.DB.GetPlayerKeyboards(player_id)
 	var keyboards []struct{ Selected bool `json:"selected"`; Keyboard}
 
 	for i := range result {
@@ -204,7 +211,7 @@ func ChangePlayerNameHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	player_id := r.Context().Value("player").(string)
-	database.ChangePlayerNameRedis(player_id, body.Name)
+	database.DB.ChangePlayerName(player_id, body.Name)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -219,14 +226,14 @@ func ChangePlayerKeyboardHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	player_id := r.Context().Value("player").(string)
-	database.ChangePlayerKeyboard(player_id, body.KeyboardId)
+	database.DB.ChangePlayerKeyboard(player_id, body.KeyboardId)
 	w.WriteHeader(http.StatusOK)
 }
 
 func PlayerUnlockedKeyboardHandler(w http.ResponseWriter, r *http.Request) {
 	player_id := r.Context().Value("player").(string)
-	stats := database.GetPlayerStatsRedis(player_id)
-	keyboards := database.GetPlayerKeyboardsRedis(player_id)
+	stats := database.DB.GetPlayerStats(player_id)
+	keyboards := database.DB.GetPlayerKeyboards(player_id)
 	keyboards_set := make(map[int]bool)
 	keyboards_unlocked := []Keyboard{}
 
@@ -242,7 +249,7 @@ func PlayerUnlockedKeyboardHandler(w http.ResponseWriter, r *http.Request) {
 
 		if  stats["Points"].(float64) >= float64(points_needed) && !ok {
 			keyboards_unlocked = append(keyboards_unlocked, Keyboards[id])
-			database.GrantPlayerKeyboard(player_id, id)
+			database.DB.GrantPlayerKeyboard(player_id, id)
 		}
 	}
 
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"server/protocol"
+	"sync"
+	"time"
+)
+
+var Games = make(map[string]*Game)
+var GamesMutex sync.RWMutex
+
+func getGame(game_id string) (*Game, bool) {
+	GamesMutex.RLock()
+	defer GamesMutex.RUnlock()
+	game, ok := Games[game_id]
+	return game, ok
+}
+
+func addGame(game *Game) {
+	GamesMutex.Lock()
+	defer GamesMutex.Unlock()
+	Games[game.Id] = game
+}
+
+func deleteGame(game_id string) {
+	GamesMutex.Lock()
+	defer GamesMutex.Unlock()
+	delete(Games, game_id)
+}
+
+// restartGameClock makes the sweep for stale games count from now
+func restartGameClock(g *Game) {
+	GamesMutex.Lock()
+	defer GamesMutex.Unlock()
+	g.CreateTime = time.Now()
+}
+
+func clearEmptyGames() {
+	var stale []*Game
+
+	GamesMutex.RLock()
+	for i := range Games {
+		if time.Since(Games[i].CreateTime) > time.Hour {
+			stale = append(stale, Games[i])
+		}
+	}
+	GamesMutex.RUnlock()
+
+	for i := range stale {
+		stale[i].call(stale[i].removeGame)
+	}
+}
+
+// playerAction is a message read from a player's websocket. The player it
+// belongs to is the client's PlayerId, which only the game loop may change.
+type playerAction struct {
+	client  *Client
+	message protocol.ClientMessage
+	err     *protocol.Error
+}
+
+// disconnectMessage is sent by the websocket handler when the connection drops
+type disconnectMessage struct{}
+
+func (m *disconnectMessage) Action() string { return "disconnect" }
+
+// run is the game's event loop. Player actions, timer ticks and calls from
+// http handlers are all handled here one at a time, so the game state is
+// never touched concurrently.
+func (g *Game) run() {
+	ticker := time.NewTicker(TickInterval)
+	defer ticker.Stop()
+
+	for !g.removed {
+		select {
+		case action := <-g.actions:
+			g.handleAction(action)
+		case fn := <-g.calls:
+			fn()
+		case now := <-ticker.C:
+			g.tick(now)
+		case <-g.done:
+			return
+		}
+	}
+}
+
+// send passes an action to the game loop. It returns false if the game has
+// already been removed.
+func (g *Game) send(action playerAction) bool {
+	select {
+	case g.actions <- action:
+		return true
+	case <-g.done:
+		return false
+	}
+}
+
+// call runs fn on the game loop and waits for it to finish. It returns false
+// if the game has already been removed.
+func (g *Game) call(fn func()) bool {
+	finished := make(chan struct{})
+
+	select {
+	case g.calls <- func() { fn(); close(finished) }:
+		<-finished
+		return true
+	case <-g.done:
+		return false
+	}
+}
+
+// tick moves the game between its timed phases, sends out player progress
+// and pushes the time left to the players once a second
+func (g *Game) tick(now time.Time) {
+	g.expireDisconnectedPlayers(now)
+	if g.removed {
+		return
+	}
+
+	phase, deadline := g.phase()
+
+	switch phase {
+	case Countdown:
+		if !now.Before(deadline) {
+			g.startGame()
+		}
+
+	case Typing:
+		g.advanceGhosts(now.Sub(g.RoundStartTime))
+		if !now.Before(deadline) || g.countTypingPlayers() == 0 {
+			g.endTyping(now)
+			if g.removed {
+				return
+			}
+		}
+
+	case Guessing:
+		if !now.Before(deadline) {
+			g.endGuessing()
+			return
+		}
+
+	case BetweenRounds:
+		if !now.Before(deadline) {
+			g.beginCountdown()
+		}
+
+	case Finished:
+		if !now.Before(deadline) {
+			g.removeGame()
+			return
+		}
+	}
+
+	g.sendPlayerUpdates()
+
+	if now.Sub(g.LastTickTime) >= time.Second {
+		g.sendTick(now)
+	}
+}
+
+func (g *Game) handleAction(a playerAction) {
+	player_id := a.client.PlayerId
+
+	// The message couldn't be decoded
+	if a.err != nil {
+		a.client.sendMessage(a.err)
+		return
+	}
+
+	if g.isSpectator(player_id) && !spectatorAllowed(a.message) {
+		g.sendError(a.client, protocol.Spectating, "Spectators can only watch the game")
+		return
+	}
+
+	switch message := a.message.(type) {
+	case *disconnectMessage:
+		g.disconnectPlayer(a.client)
+
+	case *protocol.PingAction:
+		a.client.sendMessage(&protocol.PongMessage{})
+
+	case *protocol.ResumeAction:
+		g.playerResume(message, a.client)
+
+	case *protocol.RegisterPlayerAction:
+		// A player coming back on a new connection picks up where they left
+		// off, whatever the game is doing
+		if player, ok := g.Players[player_id]; ok {
+			g.resumePlayer(a.client, player)
+			return
+		}
+
+		switch g.State {
+		case Lobby:
+			g.registerPlayer(message, a.client, player_id, a.client.Keyboard)
+			g.sendActivePlayers(player_id)
+
+			// Start public games on first player join, and ghost games once
+			// their player is in
+			if g.Type == PublicGame || g.Type == GhostGame {
+				g.startCountdown(a.client, player_id)
+			}
+
+		case Countdown:
+			g.registerPlayer(message, a.client, player_id, a.client.Keyboard)
+			if g.Type == PublicGame {
+				g.startCountdown(a.client, player_id)
+			}
+			g.sendActivePlayers(player_id)
+
+		default:
+			// Anyone new is told the game has already started
+			g.registerPlayer(message, a.client, player_id, a.client.Keyboard)
+		}
+
+	case *protocol.SpectateAction:
+		g.registerSpectator(message, a.client, player_id)
+
+	case *protocol.StartCountdownAction:
+		if g.State == Lobby || g.State == BetweenRounds {
+			g.sendActivePlayers(player_id)
+			g.requestCountdown(a.client, player_id)
+		}
+
+	case *protocol.PlayerMoveAction:
+		if g.State == Started {
+			g.playerMove(message, a.client, player_id)
+		}
+
+	case *protocol.PlayerKeysAction:
+		if g.State == Started {
+			g.playerKeys(message, a.client, player_id)
+		}
+
+	case *protocol.PlayerGuessAction:
+		if g.State == Started {
+			g.playerGuess(message, a.client, player_id)
+		}
+
+	case *protocol.RematchAction:
+		if g.State == Finished {
+			g.rematch(a.client, player_id)
+		}
+
+	case *protocol.UpdateSettingsAction:
+		if g.State == Lobby {
+			g.updateSettings(message, a.client, player_id)
+		}
+
+	case *protocol.ReadyAction:
+		if g.State == Lobby {
+			g.playerReady(message, player_id)
+		}
+
+	case *protocol.KickPlayerAction:
+		g.kickPlayer(message, a.client, player_id)
+
+	case *protocol.TransferHostAction:
+		g.transferHost(message, a.client, player_id)
+
+	case *protocol.LockRoomAction:
+		g.lockRoom(message, a.client, player_id)
+	}
+}
@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"encoding/json"
+	"server/database"
+	"server/protocol"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testSettings() protocol.GameSettings {
+	settings := DefaultSettings(PrivateGame)
+	settings.Countdown = 1
+	return settings
+}
+
+// playRound has the host start a private game and everyone type the tweet
+// and guess its author at once. mistake says whether player i slips before
+// grapheme j. It returns how the game finished and the mistakes made.
+func playRound(t *testing.T, game *Game, clients []*fakeClient, mistake func(i int, j int) bool) (protocol.StartFinishMessage, int64) {
+	var mistakes int64
+	finished := make(chan protocol.StartFinishMessage, 1)
+	var typists sync.WaitGroup
+	for i := range clients {
+		typists.Add(1)
+		go func(i int, client *fakeClient) {
+			defer typists.Done()
+
+			// The host's request starts a ready check the others answer
+			if i > 0 {
+				client.waitFor(t, "readyCheck", 5 * time.Second)
+				client.act(game, &protocol.ReadyAction{Ready: true})
+			}
+
+			var start protocol.StartGameMessage
+			if data := client.waitFor(t, "startGame", 5 * time.Second); data == nil {
+				return
+			} else if err := json.Unmarshal(data, &start); err != nil {
+				t.Error(err)
+				return
+			}
+
+			for j, grapheme := range start.Graphemes {
+				if mistake != nil && mistake(i, j) {
+					client.act(game, &protocol.PlayerMoveAction{Key: "\x01"})
+					atomic.AddInt64(&mistakes, 1)
+				}
+				client.act(game, &protocol.PlayerMoveAction{Key: grapheme})
+			}
+
+			var author string
+			game.call(func() { author = game.Author })
+			client.act(game, &protocol.PlayerGuessAction{Guess: author})
+
+			var finish protocol.StartFinishMessage
+			if data := client.waitFor(t, "startFinish", 5 * time.Second); i == 0 && data != nil {
+				json.Unmarshal(data, &finish)
+				finished <- finish
+			}
+		}(i, clients[i])
+	}
+	clients[0].act(game, &protocol.StartCountdownAction{})
+	typists.Wait()
+
+	select {
+	case finish := <-finished:
+		return finish, mistakes
+	default:
+		t.Fatal("the game never finished")
+		return protocol.StartFinishMessage{}, mistakes
+	}
+}
+
+// TestGameUnderLoad runs a full game with several players typing and
+// guessing at once, then a rematch, while other goroutines read the game
+// and sweep for stale games the way http handlers do. Run it with -race.
+func TestGameUnderLoad(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, testSettings(), 6)
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if found, ok := getGame(game.Id); ok {
+					found.call(func() { found.snapshot(); found.countTypingPlayers() })
+				}
+				clearEmptyGames()
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	// Everyone makes the odd mistake
+	playRound(t, game, clients, func(i int, j int) bool { return j % (i + 7) == 3 })
+
+	game.call(func() {
+		if game.State != Finished {
+			t.Errorf("game is %s, want %s", game.State, Finished)
+		}
+		for _, player := range game.Players {
+			if player.Status.State != Completed || !player.Status.GuessedAuthor {
+				t.Errorf("%s is %s, guessed %v", player.Id, player.Status.State, player.Status.GuessedAuthor)
+			}
+			if player.Status.CurrentLetterIdx != len(game.TweetGraphemes) {
+				t.Errorf("%s typed %d of %d", player.Id, player.Status.CurrentLetterIdx, len(game.TweetGraphemes))
+			}
+		}
+	})
+
+	clients[0].act(game, &protocol.RematchAction{})
+	for i := range clients {
+		clients[i].waitFor(t, "rematch", 5 * time.Second)
+	}
+	close(stop)
+	readers.Wait()
+
+	game.call(func() {
+		if game.State != Lobby {
+			t.Errorf("game is %s after a rematch, want %s", game.State, Lobby)
+		}
+	})
+	if _, ok := getGame(game.Id); !ok {
+		t.Error("the sweep removed a game that was in use")
+	}
+}
+
+// Every mistake is counted against a word, in the results and in history
+func TestWordErrorTotals(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, testSettings(), 2)
+
+	finish, mistakes := playRound(t, game, clients, func(i int, j int) bool { return i == 1 && j % 5 == 2 })
+	if mistakes == 0 {
+		t.Fatal("nobody made a mistake")
+	}
+
+	var sent int64
+	for _, result := range finish.Results {
+		for _, word := range result.WordErrors {
+			sent += int64(word.Errors)
+		}
+	}
+	if sent != mistakes {
+		t.Errorf("results have %d word errors, want %d", sent, mistakes)
+	}
+
+	matches, _, err := database.DB.GetPlayerMatches(clients[0].PlayerId, 0, 1)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("no match history: %v", err)
+	}
+	var stored int64
+	for _, participant := range matches[0].Participants {
+		for _, word := range participant.WordErrors {
+			stored += int64(word.Errors)
+		}
+	}
+	if stored != mistakes {
+		t.Errorf("match history has %d word errors, want %d", stored, mistakes)
+	}
+}
+
+// A rematch is its own match in history, and each keeps its own replay
+func TestRematchHistory(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, testSettings(), 2)
+
+	playRound(t, game, clients, nil)
+	clients[0].act(game, &protocol.RematchAction{})
+	for i := range clients {
+		clients[i].waitFor(t, "rematch", 5 * time.Second)
+	}
+	playRound(t, game, clients, nil)
+
+	game.call(func() {
+		if game.Rematches != 1 {
+			t.Errorf("%d rematches, want 1", game.Rematches)
+		}
+	})
+
+	matches, _, err := database.DB.GetPlayerMatches(clients[1].PlayerId, 0, 10)
+	if err != nil || len(matches) != 2 {
+		t.Fatalf("history has %d matches: %v", len(matches), err)
+	}
+	rematches := map[int]bool{}
+	for _, match := range matches {
+		rematches[match.Rematch] = true
+
+		replays, err := database.DB.GetReplays(match.GameId, match.Rematch)
+		if err != nil || len(replays) != 1 || len(replays[0].Tracks) != len(clients) {
+			t.Fatalf("replays of rematch %d are %+v: %v", match.Rematch, replays, err)
+		}
+		if replays[0].MatchId != match.Id {
+			t.Errorf("match %s of rematch %d has the replay of %s", match.Id, match.Rematch, replays[0].MatchId)
+		}
+	}
+	if !rematches[0] || !rematches[1] {
+		t.Errorf("matches are of rematches %v", rematches)
+	}
+}
+
+// When time runs out, players still typing move on to guessing and players
+// who haven't guessed are completed without a guess
+func TestDeadlines(t *testing.T) {
+	settings := testSettings()
+	settings.TimeLimit = 1
+	settings.GuessTimeLimit = 1
+	game, clients := newTestGame(t, PrivateGame, settings, 2)
+
+	clients[0].act(game, &protocol.StartCountdownAction{})
+	clients[1].waitFor(t, "readyCheck", time.Second)
+	clients[1].act(game, &protocol.ReadyAction{Ready: true})
+
+	var start protocol.StartGameMessage
+	json.Unmarshal(clients[0].waitFor(t, "startGame", 5 * time.Second), &start)
+
+	// One player finishes typing but never guesses, the other stops early
+	for _, grapheme := range start.Graphemes {
+		clients[0].act(game, &protocol.PlayerMoveAction{Key: grapheme})
+	}
+	clients[1].act(game, &protocol.PlayerMoveAction{Key: start.Graphemes[0]})
+
+	var guessing bool
+	game.call(func() { guessing = game.Players[clients[0].PlayerId].Status.State == Guessing })
+	if !guessing {
+		t.Fatal("finishing the tweet didn't move the player on to guessing")
+	}
+
+	var finish protocol.StartFinishMessage
+	if err := json.Unmarshal(clients[1].waitFor(t, "startFinish", 5 * time.Second), &finish); err != nil {
+		t.Fatal(err)
+	}
+	if len(finish.Results) != 2 {
+		t.Errorf("results are %+v", finish.Results)
+	}
+
+	game.call(func() {
+		if game.State != Finished {
+			t.Errorf("game is %s, want %s", game.State, Finished)
+		}
+		for _, player := range game.Players {
+			if player.Status.State != Completed || player.Status.GuessedAuthor {
+				t.Errorf("%s is %s, guessed %v", player.Id, player.Status.State, player.Status.GuessedAuthor)
+			}
+		}
+
+		late := game.Players[clients[1].PlayerId].Status
+		if late.CurrentLetterIdx != 1 || late.TypingEndTime.Sub(game.RoundStartTime) < time.Second {
+			t.Errorf("the late player typed %d and stopped after %v", late.CurrentLetterIdx, late.TypingEndTime.Sub(game.RoundStartTime))
+		}
+	})
+}
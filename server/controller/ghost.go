@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"math"
+	"server/database"
+	"time"
+)
+
+// In a ghost game a player races ghosts: recorded runs of the same tweet
+// played back keystroke by keystroke. Ghosts aren't in g.Players since
+// nobody is connected for them, but they are sent, scored and placed like
+// everyone else.
+
+const (
+	GhostPersonalBest = "personalBest"
+	GhostTop          = "top"
+	GhostReplay       = "replay"
+
+	MaxGhosts = MaxPlayersInGame - 1
+)
+
+type Ghost struct {
+	Player *Player
+	Run    database.Run
+
+	// The next keystroke to play and when it was typed
+	next   int
+	offset time.Duration
+}
+
+func newGhost(source string, run database.Run) *Ghost {
+	player := NewPlayer("Ghost:" + randomHex(4), run.Track.Name, false, nil, Keyboards[0])
+	player.Ghost = source
+	return &Ghost{Player: player, Run: run}
+}
+
+// validRun checks a stored run can be played back
+func validRun(run database.Run) bool {
+	track := run.Track
+	return len(track.Times) == len(track.Keys) && len(track.Marks) == len(track.Keys)
+}
+
+// racers returns the players followed by the ghosts
+func (g *Game) racers() []*Player {
+	var racers []*Player
+	for i := range g.Players {
+		racers = append(racers, g.Players[i])
+	}
+	for i := range g.Ghosts {
+		racers = append(racers, g.Ghosts[i].Player)
+	}
+	return racers
+}
+
+// ghostTimeLimit gives the slowest ghost time to finish its run
+func ghostTimeLimit(runs []database.Run) int {
+	limit := RoundTimeLimit
+	for i := range runs {
+		seconds := int(math.Ceil(float64(runs[i].Track.TypingTime) / 1000))
+		if seconds > limit {
+			limit = seconds
+		}
+	}
+
+	if limit > MaxTimeLimit {
+		limit = MaxTimeLimit
+	}
+	return limit
+}
+
+// advanceGhosts plays every ghost's keystrokes up to elapsed into the round
+func (g *Game) advanceGhosts(elapsed time.Duration) {
+	for i := range g.Ghosts {
+		g.playGhost(g.Ghosts[i], elapsed)
+	}
+}
+
+// finishGhosts plays the rest of every ghost's run, so their results are
+// the ones they recorded even when the round ends first
+func (g *Game) finishGhosts() {
+	g.advanceGhosts(time.Duration(math.MaxInt64))
+}
+
+func (g *Game) playGhost(ghost *Ghost, elapsed time.Duration) {
+	player := ghost.Player
+	track := ghost.Run.Track
+
+	for ghost.next < len(track.Keys) && player.Status.State == Typing {
+		offset := ghost.offset + time.Duration(track.Times[ghost.next]) * time.Millisecond
+		if offset > elapsed {
+			return
+		}
+
+		key, mark := track.Keys[ghost.next], track.Marks[ghost.next]
+		ghost.offset = offset
+		ghost.next += 1
+
+		// Keys the server threw away didn't change anything
+		if mark == database.KeyRejected {
+			recordKey(player, key, offset, database.KeyRejected)
+			continue
+		}
+		g.applyKey(player, key, offset)
+	}
+
+	// A run that didn't finish the tweet stops when its typing did
+	typing_time := time.Duration(track.TypingTime) * time.Millisecond
+	if player.Status.State == Typing && ghost.next == len(track.Keys) && elapsed >= typing_time {
+		player.Status.UncorrectedErrors = g.bufferErrors(player)
+		g.finishTyping(player, g.RoundStartTime.Add(typing_time))
+	}
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"server/database"
+	"server/text"
+	"strings"
+)
+
+// GhostRequest picks a run to race. Personal bests and top runs are of the
+// game's tweet; a replay ghost is track Track of a round of any game, such
+// as one a friend shared, with Rematch picking which of its matches.
+type GhostRequest struct {
+	Source  string `json:"source"`
+	GameId  string `json:"gameId"`
+	Rematch int    `json:"rematch"`
+	Round   int    `json:"round"`
+	Track   int    `json:"track"`
+}
+
+// GhostGameRequest sets up a ghost game. The tweet and typing mode come
+// from replay ghosts when there are any; otherwise TweetId is needed.
+type GhostGameRequest struct {
+	TweetId    string         `json:"tweetId"`
+	TypingMode string         `json:"typingMode"`
+	Ghosts     []GhostRequest `json:"ghosts"`
+}
+
+// replayRun returns a track of a stored replay as a run, with the text
+// options it was typed with
+func replayRun(request GhostRequest) (database.Run, text.Options, bool) {
+	replays, err := database.DB.GetReplays(request.GameId, request.Rematch)
+	if err != nil {
+		return database.Run{}, text.Options{}, false
+	}
+
+	for i := range replays {
+		if replays[i].Round == request.Round && request.Track >= 0 && request.Track < len(replays[i].Tracks) {
+			return database.Run{
+				TweetId: replays[i].TweetId,
+				TypingMode: replays[i].TypingMode,
+				Track: replays[i].Tracks[request.Track],
+			}, replays[i].Text, true
+		}
+	}
+	return database.Run{}, text.Options{}, false
+}
+
+// CreateGhostGameHandler creates a solo game against recorded runs
+func CreateGhostGameHandler(w http.ResponseWriter, r *http.Request) {
+	var request GhostGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid ghost game", http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Ghosts) == 0 || len(request.Ghosts) > MaxGhosts {
+		http.Error(w, fmt.Sprintf("ghosts must hold between 1 and %d runs", MaxGhosts), http.StatusBadRequest)
+		return
+	}
+
+	player_id := r.Context().Value("player").(string)
+	tweet_id, typing_mode := request.TweetId, request.TypingMode
+	if typing_mode == "" {
+		typing_mode = StrictTyping
+	}
+
+	// Replays fix the tweet, typing mode and text options, so they go first
+	text_options := text.DefaultOptions
+	var runs []database.Run
+	var sources []string
+	for _, ghost := range request.Ghosts {
+		if ghost.Source != GhostReplay {
+			continue
+		}
+
+		run, replay_options, ok := replayRun(ghost)
+		if !ok {
+			http.Error(w, "replay not found", http.StatusNotFound)
+			return
+		}
+
+		if len(runs) == 0 {
+			if request.TweetId == "" {
+				tweet_id, typing_mode = run.TweetId, run.TypingMode
+			}
+			text_options = replay_options
+		}
+		if run.TweetId != tweet_id || run.TypingMode != typing_mode || replay_options != text_options {
+			http.Error(w, "ghosts must have typed the same tweet in the same typing mode", http.StatusBadRequest)
+			return
+		}
+
+		runs = append(runs, run)
+		sources = append(sources, GhostReplay)
+	}
+
+	tweet_number, ok := tweetNumber(tweet_id)
+	if !ok {
+		http.Error(w, "unknown tweet", http.StatusBadRequest)
+		return
+	}
+	if typing_mode != StrictTyping && typing_mode != RealTyping {
+		http.Error(w, "typingMode must be StrictTyping or RealTyping", http.StatusBadRequest)
+		return
+	}
+
+	for _, ghost := range request.Ghosts {
+		var run database.Run
+		var err error
+
+		if ghost.Source == GhostReplay {
+			continue
+		} else if text_options != text.DefaultOptions {
+			http.Error(w, "personal bests and top runs are only kept for the default text options", http.StatusBadRequest)
+			return
+		}
+
+		switch ghost.Source {
+		case GhostPersonalBest:
+			run, err = database.DB.GetPersonalBest(tweet_id, typing_mode, player_id)
+		case GhostTop:
+			run, err = database.DB.GetTopRun(tweet_id, typing_mode)
+		default:
+			http.Error(w, "unknown ghost source", http.StatusBadRequest)
+			return
+		}
+
+		if err == database.ErrNotFound {
+			http.Error(w, "no " + ghost.Source + " run of this tweet", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "failed to get run", http.StatusInternalServerError)
+			return
+		}
+
+		runs = append(runs, run)
+		sources = append(sources, ghost.Source)
+	}
+
+	for i := range runs {
+		if !validRun(runs[i]) {
+			http.Error(w, "run can't be played back", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	settings := DefaultSettings(GhostGame)
+	settings.MaxPlayers = 1
+	settings.GuessingEnabled = false
+	settings.TypingMode = typing_mode
+	settings.TimeLimit = ghostTimeLimit(runs)
+	settings.Text = &text_options
+
+	game, err := NewGame(player_id, GhostGame, settings)
+	if err != nil {
+		http.Error(w, "failed to create game", http.StatusBadRequest)
+		return
+	}
+
+	game.call(func() {
+		game.setTweet(loadTweet(tweet_number, game.Settings))
+		for i := range runs {
+			ghost := newGhost(sources[i], runs[i])
+			game.Ghosts[ghost.Player.Id] = ghost
+		}
+	})
+
+	database.DB.IncrementGamesCreated()
+	addGame(game)
+	shortened_game_id := strings.Split(game.Id, ":")[1]
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shortened_game_id)
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"reflect"
+	"server/database"
+	"testing"
+	"time"
+)
+
+// recordRun has a player type the first count graphemes of the game's tweet,
+// one every 150ms with a mistake they fix on the way, and returns their run
+func recordRun(g *Game, count int) (*Player, database.Run) {
+	g.RoundStartTime = time.Now()
+	player := NewPlayer("Player:recorded", "recorded", false, nil, Keyboards[0])
+	player.Status.State = Typing
+	player.Status.TypingStartTime = g.RoundStartTime
+
+	offset := time.Duration(0)
+	for i, grapheme := range g.TweetGraphemes[:count] {
+		if i == 2 {
+			offset += 150 * time.Millisecond
+			g.applyKey(player, "x", offset)
+			offset += 150 * time.Millisecond
+			g.applyKey(player, BackspaceKey, offset)
+		}
+		offset += 150 * time.Millisecond
+		g.applyKey(player, grapheme, offset)
+	}
+
+	if player.Status.State == Typing {
+		g.finishTyping(player, g.RoundStartTime.Add(offset + time.Second))
+	}
+	return player, database.Run{TweetId: g.TweetId, Track: track(player, g.typedTweet(player))}
+}
+
+// raceGhost sets a game up to play a run back
+func raceGhost(run database.Run) (*Game, *Ghost) {
+	g := reviewGame()
+	g.Ghosts = make(map[string]*Ghost)
+	g.RoundStartTime = time.Now()
+
+	ghost := newGhost(GhostPersonalBest, run)
+	ghost.Player.Status.State = Typing
+	ghost.Player.Status.TypingStartTime = g.RoundStartTime
+	g.Ghosts[ghost.Player.Id] = ghost
+	return g, ghost
+}
+
+func TestGhostPlayback(t *testing.T) {
+	recorded, run := recordRun(reviewGame(), len(reviewGame().TweetGraphemes))
+	g, ghost := raceGhost(run)
+	status := &ghost.Player.Status
+
+	// Keys play at the times they were typed
+	g.advanceGhosts(149 * time.Millisecond)
+	if len(status.Keystrokes) != 0 {
+		t.Errorf("%d keys played before the first was typed", len(status.Keystrokes))
+	}
+	g.advanceGhosts(750 * time.Millisecond)
+	if len(status.Keystrokes) != 5 || status.CurrentLetterIdx != 3 || status.State != Typing {
+		t.Errorf("after 750ms the ghost played %d keys to %d and is %s", len(status.Keystrokes), status.CurrentLetterIdx, status.State)
+	}
+
+	// and the run ends as it was recorded
+	g.advanceGhosts(time.Minute)
+	if status.State == Typing || status.CurrentLetterIdx != len(g.TweetGraphemes) {
+		t.Errorf("the ghost is %s at %d of %d", status.State, status.CurrentLetterIdx, len(g.TweetGraphemes))
+	}
+	if typing := status.TypingEndTime.Sub(status.TypingStartTime); typing != recorded.Status.TypingEndTime.Sub(recorded.Status.TypingStartTime) {
+		t.Errorf("the ghost typed for %v, the player for %v", typing, recorded.Status.TypingEndTime.Sub(recorded.Status.TypingStartTime))
+	}
+	if status.CorrectedErrors != recorded.Status.CorrectedErrors || status.KeysTyped != recorded.Status.KeysTyped {
+		t.Errorf("the ghost made %d corrections in %d keys, the player %d in %d",
+			status.CorrectedErrors, status.KeysTyped, recorded.Status.CorrectedErrors, recorded.Status.KeysTyped)
+	}
+
+	played := track(ghost.Player, g.typedTweet(ghost.Player))
+	if !reflect.DeepEqual(played.Times, run.Track.Times) || !reflect.DeepEqual(played.Keys, run.Track.Keys) || played.Marks != run.Track.Marks {
+		t.Errorf("the ghost played %+v, recorded %+v", played, run.Track)
+	}
+}
+
+// A run that didn't finish the tweet stops where the player did
+func TestUnfinishedGhost(t *testing.T) {
+	recorded, run := recordRun(reviewGame(), 5)
+	g, ghost := raceGhost(run)
+	status := &ghost.Player.Status
+
+	typing_time := time.Duration(run.Track.TypingTime) * time.Millisecond
+	g.advanceGhosts(typing_time - time.Millisecond)
+	if status.State != Typing || status.CurrentLetterIdx != 5 {
+		t.Errorf("before the run's end the ghost is %s at %d", status.State, status.CurrentLetterIdx)
+	}
+
+	g.finishGhosts()
+	if status.State == Typing || status.CurrentLetterIdx != recorded.Status.CurrentLetterIdx {
+		t.Errorf("the ghost is %s at %d, the player stopped at %d", status.State, status.CurrentLetterIdx, recorded.Status.CurrentLetterIdx)
+	}
+	if status.TypingEndTime.Sub(g.RoundStartTime) != typing_time {
+		t.Errorf("the ghost stopped after %v, want %v", status.TypingEndTime.Sub(g.RoundStartTime), typing_time)
+	}
+}
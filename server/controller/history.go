@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"encoding/json"
+	"server/database"
+	"server/protocol"
+	"sort"
+	"strings"
+	"time"
+)
+
+// recordMatch keeps the round that just ended in the match history of
+// everyone who played it
+func (g *Game) recordMatch(results []protocol.PlayerResult) {
+	settings, _ := json.Marshal(g.Settings)
+
+	match := database.Match{
+		GameId: strings.Split(g.Id, ":")[1],
+		Rematch: g.Rematches,
+		Type: g.Type,
+		Ranked: g.Ranked,
+		Round: g.Round,
+		Rounds: g.Settings.Rounds,
+		TweetId: g.TweetId,
+		Tweet: g.Tweet,
+		Author: g.Author,
+		AuthorHandle: g.AuthorHandle,
+		Settings: settings,
+		StartTime: g.RoundStartTime,
+		EndTime: time.Now(),
+	}
+
+	rating_changes := make(map[string]float64)
+	word_errors := make(map[string][]database.WordError)
+	for i := range results {
+		rating_changes[results[i].Id] = results[i].RatingChange
+		for _, word := range results[i].WordErrors {
+			word_errors[results[i].Id] = append(word_errors[results[i].Id], database.WordError(word))
+		}
+	}
+
+	for _, player := range g.racers() {
+		status := &player.Status
+
+		participant := database.MatchParticipant{
+			PlayerId: player.Id,
+			Name: player.Name,
+			Speed: status.Result.NetWPM,
+			RawSpeed: status.Result.GrossWPM,
+			Accuracy: status.Result.Accuracy,
+			Consistency: status.Result.Consistency,
+			Progress: status.CurrentLetterIdx,
+			Finished: status.CurrentLetterIdx == len(g.TweetGraphemes),
+			Guess: status.Guess,
+			GuessedAuthor: status.GuessedAuthor,
+			Placement: status.Placement,
+			Points: status.Points,
+			RatingChange: rating_changes[player.PublicId],
+			WordErrors: word_errors[player.PublicId],
+			Voided: status.Verdict.Void,
+		}
+
+		// A voided speed isn't one the player typed
+		if participant.Voided {
+			participant.Speed = 0
+			participant.RawSpeed = 0
+			participant.Accuracy = 0
+			participant.Consistency = 0
+		}
+		match.Participants = append(match.Participants, participant)
+	}
+
+	sort.Slice(match.Participants, func(i, j int) bool {
+		return match.Participants[i].Placement < match.Participants[j].Placement
+	})
+
+	if match_id, err := database.DB.SaveMatch(match); err == nil {
+		g.saveReplay(match_id)
+	}
+}
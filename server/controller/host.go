@@ -0,0 +1,182 @@
+package controller
+
+import "server/protocol"
+
+// Private games have a host, the player with Creator set. Only the host can
+// change the game, and the role passes on if the host drops out.
+
+func (g *Game) isHost(player_id string) bool {
+	player, ok := g.Players[player_id]
+	return ok && player.Creator && g.Type == PrivateGame
+}
+
+func (g *Game) requireHost(client *Client, player_id string) bool {
+	if !g.isHost(player_id) {
+		g.sendError(client, protocol.NotHost, "Only the host can do that")
+		return false
+	}
+	return true
+}
+
+// findPlayer looks a player up by the id the other players know them by
+func (g *Game) findPlayer(public_id string) (*Player, bool) {
+	for i := range g.Players {
+		if g.Players[i].PublicId == public_id {
+			return g.Players[i], true
+		}
+	}
+	return nil, false
+}
+
+// requestCountdown starts the countdown for the host. In the lobby it first
+// runs a ready check, and the countdown starts once everyone is ready.
+func (g *Game) requestCountdown(client *Client, player_id string) {
+	// Public games count down on their own
+	if g.Type == PublicGame {
+		g.startCountdown(client, player_id)
+		return
+	}
+
+	if !g.requireHost(client, player_id) {
+		return
+	}
+
+	if g.State != Lobby {
+		g.startCountdown(client, player_id)
+		return
+	}
+
+	g.Players[player_id].Ready = true
+	g.ReadyCheck = true
+	g.checkReady()
+
+	if g.ReadyCheck {
+		g.broadcastMessage(&protocol.ReadyCheckMessage{})
+	}
+}
+
+func (g *Game) playerReady(data *protocol.ReadyAction, player_id string) {
+	if player, ok := g.Players[player_id]; ok {
+		player.Ready = data.Ready
+		g.checkReady()
+	}
+}
+
+// checkReady starts the countdown once every connected player is ready
+func (g *Game) checkReady() {
+	if !g.ReadyCheck || g.State != Lobby {
+		return
+	}
+
+	for i := range g.Players {
+		if g.Players[i].Connection == Connected && !g.Players[i].Ready {
+			return
+		}
+	}
+
+	g.ReadyCheck = false
+	g.beginCountdown()
+}
+
+// resetReady cancels the ready check, e.g. after the settings change
+func (g *Game) resetReady() {
+	g.ReadyCheck = false
+	for i := range g.Players {
+		g.Players[i].Ready = false
+	}
+}
+
+func (g *Game) kickPlayer(data *protocol.KickPlayerAction, client *Client, player_id string) {
+	if !g.requireHost(client, player_id) {
+		return
+	}
+
+	target, ok := g.findPlayer(data.PlayerId)
+	if !ok || target.Id == player_id {
+		g.sendError(client, protocol.InvalidData, "Can't kick that player")
+		return
+	}
+
+	// Kicked players can't join again
+	g.Kicked[target.Id] = true
+	g.sendError(target.Client, protocol.Kicked, "You were removed from the game by the host")
+	target.Client.close()
+
+	g.unregisterPlayer(target.Id)
+	g.playersLeft()
+}
+
+func (g *Game) transferHost(data *protocol.TransferHostAction, client *Client, player_id string) {
+	if !g.requireHost(client, player_id) {
+		return
+	}
+
+	target, ok := g.findPlayer(data.PlayerId)
+	if !ok || target.Id == player_id {
+		g.sendError(client, protocol.InvalidData, "Can't make that player host")
+		return
+	}
+
+	g.Players[player_id].Creator = false
+	target.Creator = true
+	g.sendActivePlayers(player_id)
+}
+
+func (g *Game) lockRoom(data *protocol.LockRoomAction, client *Client, player_id string) {
+	if !g.requireHost(client, player_id) {
+		return
+	}
+
+	g.Locked = data.Locked
+	g.broadcastMessage(&protocol.RoomLockedMessage{Locked: g.Locked})
+}
+
+// ensureHost hands the host role to the connected player who has been in the
+// game longest when the host has left or dropped. It reports whether the
+// host changed.
+func (g *Game) ensureHost() bool {
+	if g.Type != PrivateGame {
+		return false
+	}
+
+	var host *Player
+	var next *Player
+
+	for i := range g.Players {
+		player := g.Players[i]
+		if player.Creator {
+			host = player
+			continue
+		}
+		if player.Connection == Connected && (next == nil || player.JoinTime.Before(next.JoinTime)) {
+			next = player
+		}
+	}
+
+	if (host != nil && host.Connection == Connected) || next == nil {
+		return false
+	}
+
+	if host != nil {
+		host.Creator = false
+	}
+	next.Creator = true
+	return true
+}
+
+// playersLeft tidies up after players are removed from the game
+func (g *Game) playersLeft() {
+	if len(g.Players) == 0 {
+		g.removeGame()
+		return
+	}
+
+	g.ensureHost()
+	g.sendActivePlayers("")
+	g.checkReady()
+
+	// The player may have been the last one the game was waiting on
+	if g.State == Started && g.countCompletedPlayers() == len(g.Players) {
+		g.startFinish("")
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"encoding/json"
+	"server/protocol"
+	"testing"
+	"time"
+)
+
+// publicId is the id other players know the client's player by
+func publicId(game *Game, client *fakeClient) string {
+	var id string
+	game.call(func() { id = game.Players[client.PlayerId].PublicId })
+	return id
+}
+
+// expectError waits for an error and checks its code
+func expectError(t *testing.T, client *fakeClient, code protocol.ErrorCode) {
+	t.Helper()
+	var err protocol.Error
+	json.Unmarshal(client.waitFor(t, "error", time.Second), &err)
+	if err.Code != code {
+		t.Errorf("got %+v, want %s", err, code)
+	}
+}
+
+// After handing the host role on, only the new host can start a rematch
+func TestRematchAfterTransfer(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, DefaultSettings(PrivateGame), 2)
+
+	clients[0].act(game, &protocol.TransferHostAction{PlayerId: publicId(game, clients[1])})
+	game.call(func() {
+		game.State = Finished
+		game.RematchDeadline = time.Now().Add(RematchWindow * time.Second)
+	})
+
+	clients[0].act(game, &protocol.RematchAction{})
+	expectError(t, clients[0], protocol.NotHost)
+
+	clients[1].act(game, &protocol.RematchAction{})
+	clients[0].waitFor(t, "rematch", time.Second)
+	game.call(func() {
+		if game.State != Lobby || game.Rematches != 1 {
+			t.Errorf("game is %s after %d rematches", game.State, game.Rematches)
+		}
+	})
+}
+
+func TestHostControls(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, DefaultSettings(PrivateGame), 4)
+	host, player, kicked, other := clients[0], clients[1], clients[2], clients[3]
+
+	// Only the host can kick
+	player.act(game, &protocol.KickPlayerAction{PlayerId: publicId(game, kicked)})
+	expectError(t, player, protocol.NotHost)
+
+	host.act(game, &protocol.KickPlayerAction{PlayerId: publicId(game, kicked)})
+	expectError(t, kicked, protocol.Kicked)
+	game.call(func() {
+		if _, ok := game.Players[kicked.PlayerId]; ok {
+			t.Error("the kicked player is still in the game")
+		}
+	})
+
+	// and kicked players stay out
+	back := newFakeClient(kicked.PlayerId)
+	back.act(game, &protocol.RegisterPlayerAction{Name: "back"})
+	expectError(t, back, protocol.Kicked)
+
+	// The old host loses their controls when they hand them on
+	host.act(game, &protocol.TransferHostAction{PlayerId: publicId(game, player)})
+	host.act(game, &protocol.LockRoomAction{Locked: true})
+	expectError(t, host, protocol.NotHost)
+
+	player.act(game, &protocol.LockRoomAction{Locked: true})
+	var locked protocol.RoomLockedMessage
+	json.Unmarshal(other.waitFor(t, "roomLocked", time.Second), &locked)
+	if !locked.Locked {
+		t.Error("the room wasn't locked")
+	}
+
+	stranger := newFakeClient("Guest:stranger")
+	stranger.act(game, &protocol.RegisterPlayerAction{Name: "stranger"})
+	expectError(t, stranger, protocol.RoomLocked)
+
+	player.act(game, &protocol.LockRoomAction{Locked: false})
+	stranger.act(game, &protocol.RegisterPlayerAction{Name: "stranger"})
+	stranger.waitFor(t, "sendSession", time.Second)
+
+	// The host role passes to whoever has been in longest when the host drops
+	player.act(game, &disconnectMessage{})
+	game.call(func() {
+		if !game.isHost(host.PlayerId) || game.isHost(player.PlayerId) {
+			t.Error("the host role didn't pass on when the host dropped")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"server/protocol"
+	"time"
+)
+
+// playerKeys applies a batch of keystrokes timed by the client, so network
+// jitter doesn't change the player's speed
+func (g *Game) playerKeys(data *protocol.PlayerKeysAction, client *Client, player_id string) {
+	player, ok := g.Players[player_id]
+	if !ok || player.Status.State != Typing {
+		return
+	}
+
+	elapsed := time.Since(g.RoundStartTime)
+	if err := g.validateKeystrokes(player, data.Keys, elapsed); err != nil {
+		keys := make([]string, len(data.Keys))
+		for i := range data.Keys {
+			keys[i] = data.Keys[i].Key
+		}
+		recordRejected(player, keys, elapsed)
+
+		client.sendMessage(err)
+		return
+	}
+
+	for i := range data.Keys {
+		if player.Status.State != Typing {
+			break
+		}
+		g.applyKey(player, data.Keys[i].Key, time.Duration(data.Keys[i].Offset) * time.Millisecond)
+	}
+}
+
+// validateKeystrokes checks a batch against what the server has seen. The
+// client starts its clock when it receives startGame, so its offsets should
+// never be ahead of the server's.
+func (g *Game) validateKeystrokes(player *Player, keys []protocol.Keystroke, elapsed time.Duration) *protocol.Error {
+	previous := player.Status.LastKeyOffset
+	has_previous := player.Status.KeysTyped > 0
+
+	for i := range keys {
+		offset := time.Duration(keys[i].Offset) * time.Millisecond
+
+		if offset > elapsed + KeystrokeClockTolerance {
+			return protocol.NewError(protocol.InvalidKeystrokes, "keystroke is in the future")
+		}
+		if offset < elapsed - MaxKeystrokeDelay {
+			return protocol.NewError(protocol.InvalidKeystrokes, "keystroke was sent too late")
+		}
+		if has_previous && offset < previous {
+			return protocol.NewError(protocol.InvalidKeystrokes, "keystroke is older than the last one")
+		}
+	}
+
+	// No one types faster than one key every MinKeystrokeInterval on average
+	first := time.Duration(keys[0].Offset) * time.Millisecond
+	count := len(keys) - 1
+	if has_previous {
+		first = previous
+		count = len(keys)
+	}
+
+	last := time.Duration(keys[len(keys) - 1].Offset) * time.Millisecond
+	if last - first < time.Duration(count) * MinKeystrokeInterval {
+		return protocol.NewError(protocol.InvalidKeystrokes, "keystrokes are too fast")
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"server/protocol"
+	"testing"
+	"time"
+)
+
+// keystrokes types "a" at each offset, in milliseconds
+func keystrokes(offsets ...int64) []protocol.Keystroke {
+	keys := make([]protocol.Keystroke, len(offsets))
+	for i := range offsets {
+		keys[i] = protocol.Keystroke{Key: "a", Offset: offsets[i]}
+	}
+	return keys
+}
+
+func TestValidateKeystrokes(t *testing.T) {
+	tolerance := int64(KeystrokeClockTolerance / time.Millisecond)
+	delay := int64(MaxKeystrokeDelay / time.Millisecond)
+	interval := int64(MinKeystrokeInterval / time.Millisecond)
+
+	// The server has seen 20 seconds of the round
+	const elapsed = 20000
+
+	tests := []struct {
+		name  string
+		// Offset of the player's last key, or -1 if they haven't typed
+		last  int64
+		keys  []protocol.Keystroke
+		valid bool
+	}{
+		{"first batch", -1, keystrokes(19000, 19150, 19300), true},
+		{"one key", -1, keystrokes(19900), true},
+		{"after the last batch", 18000, keystrokes(18200, 18400), true},
+		{"same time as the last key", 18000, keystrokes(18000 + interval), true},
+
+		{"just inside the clock tolerance", -1, keystrokes(elapsed + tolerance), true},
+		{"in the future", -1, keystrokes(19000, elapsed + tolerance + 1), false},
+
+		{"as late as allowed", -1, keystrokes(elapsed - delay, elapsed - delay + 100), true},
+		{"late batch", -1, keystrokes(elapsed - delay - 1, elapsed - delay + 100), false},
+
+		{"older than the last batch", 19000, keystrokes(18900, 19100), false},
+		{"out of order across batches", 19500, keystrokes(19400), false},
+
+		{"as fast as allowed", -1, keystrokes(19000, 19000 + interval, 19000 + 2 * interval), true},
+		{"too fast", -1, keystrokes(19000, 19001, 19002, 19003), false},
+		{"too fast after the last batch", 19000, keystrokes(19001, 19002), false},
+		{"keys at once after a pause", 18000, keystrokes(19000, 19000, 19000), true},
+	}
+
+	for _, test := range tests {
+		g := &Game{}
+		player := NewPlayer("Player:keys", "keys", false, nil, Keyboards[0])
+		if test.last >= 0 {
+			player.Status.KeysTyped = 1
+			player.Status.LastKeyOffset = time.Duration(test.last) * time.Millisecond
+		}
+
+		err := g.validateKeystrokes(player, test.keys, elapsed * time.Millisecond)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: got %v, want valid %v", test.name, err, test.valid)
+		}
+		if err != nil && err.Code != protocol.InvalidKeystrokes {
+			t.Errorf("%s: rejected with %s", test.name, err.Code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/database"
+	"strconv"
+)
+
+const (
+	LeaderboardPageSize    = 20
+	MaxLeaderboardPageSize = 100
+	// Nobody pages further than this, and offsets past it could overflow
+	MaxPage = 10000
+)
+
+type LeaderboardResponse struct {
+	Board    string                      `json:"board"`
+	Window   string                      `json:"window"`
+	Page     int                         `json:"page"`
+	PageSize int                         `json:"pageSize"`
+	Total    int                         `json:"total"`
+	Entries  []database.LeaderboardEntry `json:"entries"`
+	// Where the player asking is, if they are on the board
+	Player   *database.LeaderboardEntry  `json:"player"`
+}
+
+func validLeaderboard(board string, window string) bool {
+	board_ok, window_ok := false, false
+	for i := range database.Leaderboards {
+		board_ok = board_ok || database.Leaderboards[i] == board
+	}
+	for i := range database.LeaderboardWindows {
+		window_ok = window_ok || database.LeaderboardWindows[i] == window
+	}
+	return board_ok && window_ok
+}
+
+// queryInt reads a positive number from the query, or the default when it
+// isn't there
+func queryInt(r *http.Request, name string, default_value int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return default_value, true
+	}
+
+	number, err := strconv.Atoi(value)
+	return number, err == nil && number > 0
+}
+
+// queryPage reads the page and page size from the query, which must be
+// within MaxPage and max_size
+func queryPage(r *http.Request, default_size int, max_size int) (int, int, bool) {
+	page, page_ok := queryInt(r, "page", 1)
+	page_size, size_ok := queryInt(r, "pageSize", default_size)
+	return page, page_size, page_ok && size_ok && page <= MaxPage && page_size <= max_size
+}
+
+func GetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	board := r.URL.Query().Get("board")
+	if board == "" { board = database.PointsBoard }
+
+	window := r.URL.Query().Get("window")
+	if window == "" { window = database.AllTime }
+
+	if !validLeaderboard(board, window) {
+		http.Error(w, "unknown leaderboard", http.StatusBadRequest)
+		return
+	}
+
+	page, page_size, ok := queryPage(r, LeaderboardPageSize, MaxLeaderboardPageSize)
+	if !ok {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+		return
+	}
+
+	leaderboard, err := database.DB.GetLeaderboard(board, window, (page - 1) * page_size, page_size)
+	if err != nil {
+		http.Error(w, "failed to get leaderboard", http.StatusInternalServerError)
+		return
+	}
+
+	response := LeaderboardResponse{
+		Board: board,
+		Window: window,
+		Page: page,
+		PageSize: page_size,
+		Total: leaderboard.Total,
+		Entries: leaderboard.Entries,
+	}
+
+	player_id := r.Context().Value("player").(string)
+	if entry, ok := database.DB.GetLeaderboardRank(board, window, player_id); ok {
+		response.Player = &entry
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"server/database"
+	"server/protocol"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	uuid "github.com/satori/go.uuid"
+)
+
+// testServer takes websocket connections for fake clients and reads from
+// them until they are closed
+var testServer *httptest.Server
+
+func TestMain(m *testing.M) {
+	database.DB = database.NewMemoryStore()
+	if err := LoadTweets("../users.json", "../tweets.json"); err != nil {
+		log.Fatal(err)
+	}
+
+	testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	code := m.Run()
+	testServer.Close()
+	os.Exit(code)
+}
+
+// testConn opens a websocket to the test server
+func testConn() *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws" + strings.TrimPrefix(testServer.URL, "http"), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return conn
+}
+
+// fakeClient is a player without a websocket. Everything the game sends it
+// is kept so tests can wait for messages.
+type fakeClient struct {
+	*Client
+
+	mu       sync.Mutex
+	messages []protocol.Envelope
+	received chan struct{}
+}
+
+func newFakeClient(player_id string) *fakeClient {
+	f := &fakeClient{
+		Client: NewClient(testConn(), player_id, Keyboards[0]),
+		received: make(chan struct{}, 1),
+	}
+
+	go func() {
+		for raw := range f.send {
+			var envelope protocol.Envelope
+			json.Unmarshal(raw, &envelope)
+
+			f.mu.Lock()
+			f.messages = append(f.messages, envelope)
+			f.mu.Unlock()
+
+			select {
+			case f.received <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return f
+}
+
+// waitFor returns the data of the first message with the action the test
+// hasn't already taken, failing the test if it doesn't arrive in time
+func (f *fakeClient) waitFor(t *testing.T, action string, timeout time.Duration) json.RawMessage {
+	deadline := time.After(timeout)
+	for {
+		f.mu.Lock()
+		for i, message := range f.messages {
+			if message.Action == action {
+				f.messages = append(f.messages[:i:i], f.messages[i + 1:]...)
+				f.mu.Unlock()
+				return message.Data
+			}
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-f.received:
+		case <-deadline:
+			t.Errorf("%s never got %s", f.PlayerId, action)
+			return nil
+		}
+	}
+}
+
+func (f *fakeClient) act(g *Game, message protocol.ClientMessage) bool {
+	return g.send(playerAction{client: f.Client, message: message})
+}
+
+// newTestGame creates a game and registers a signed in player for each
+// client, the first of them the host
+func newTestGame(t *testing.T, game_type string, settings protocol.GameSettings, players int) (*Game, []*fakeClient) {
+	var clients []*fakeClient
+	for i := 0; i < players; i++ {
+		player_id, err := database.DB.CreatePlayer("test-" + uuid.NewV4().String(), fmt.Sprintf("player %d", i), "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, newFakeClient(player_id))
+	}
+
+	game, err := NewGame(clients[0].PlayerId, game_type, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addGame(game)
+	t.Cleanup(func() { game.call(game.removeGame) })
+
+	for i := range clients {
+		clients[i].act(game, &protocol.RegisterPlayerAction{Name: fmt.Sprintf("player %d", i)})
+		clients[i].waitFor(t, "sendSession", time.Second)
+	}
+	return game, clients
+}
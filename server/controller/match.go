@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"server/database"
+	"server/protocol"
+	"sort"
+	"time"
+)
+
+// Private games can be played as a match of several rounds. Between rounds
+// the room stays open with a new tweet, and points carry over from round to
+// round. Each round is recorded in player stats like a single game.
+
+// startBreak shows everyone how the round went and sets up the next one,
+// which counts down on its own once the break is over
+func (g *Game) startBreak(results []protocol.PlayerResult) {
+	g.State = BetweenRounds
+	g.NextRoundTime = time.Now().Add(RoundBreak * time.Second)
+	database.DB.UpdateGameStatus(g.Id, BetweenRounds)
+
+	g.broadcastMessage(&protocol.RoundFinishMessage{
+		State: BetweenRounds,
+		Author: g.Author,
+		AuthorHandle: g.AuthorHandle,
+		Round: g.Round,
+		Rounds: g.Settings.Rounds,
+		Clock: RoundBreak,
+		Results: results,
+		Scoreboard: matchStandings(g.scoreboard()),
+	})
+	g.sendActivePlayers("")
+
+	g.Round += 1
+	g.resetRound()
+}
+
+// resetRound clears everyone's progress and picks a new tweet
+func (g *Game) resetRound() {
+	for i := range g.Players {
+		g.Players[i].Status = *NewPlayerGameStatus()
+	}
+
+	g.RoundStartTime = time.Time{}
+	g.TypingDeadline = time.Time{}
+	g.GuessingDeadline = time.Time{}
+	g.newTweet()
+}
+
+// rematch takes a finished private game back to the lobby with the same
+// code and players, a fresh tweet and a clean scoreboard
+func (g *Game) rematch(client *Client, player_id string) {
+	if !g.isHost(player_id) {
+		g.sendError(client, protocol.NotHost, "Only the host can start a rematch")
+		return
+	}
+
+	for i := range g.Players {
+		g.Players[i].MatchPoints = 0
+		g.Players[i].RoundsWon = 0
+	}
+
+	g.State = Lobby
+	g.Rematches += 1
+	g.Round = 1
+	g.Winner = ""
+	g.RematchDeadline = time.Time{}
+	g.resetReady()
+	// The sweep for stale games counts from the rematch
+	restartGameClock(g)
+	g.resetRound()
+	database.DB.UpdateGameStatus(g.Id, Lobby)
+
+	g.broadcastMessage(&protocol.RematchMessage{State: Lobby})
+	g.broadcastMessage((*protocol.SendSettingsMessage)(&g.Settings))
+	g.sendActivePlayers(player_id)
+}
+
+// scoreboard returns the players by points across the match, using rounds
+// won to break ties
+func (g *Game) scoreboard() []*Player {
+	var players []*Player
+	for i := range g.Players {
+		players = append(players, g.Players[i])
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		if players[i].MatchPoints != players[j].MatchPoints {
+			return players[i].MatchPoints > players[j].MatchPoints
+		}
+		return players[i].RoundsWon > players[j].RoundsWon
+	})
+
+	return players
+}
+
+func matchStandings(players []*Player) []protocol.MatchStanding {
+	standings := []protocol.MatchStanding{}
+	for i := range players {
+		standings = append(standings, protocol.MatchStanding{
+			Id: players[i].PublicId,
+			Name: players[i].Name,
+			Points: players[i].MatchPoints,
+			RoundsWon: players[i].RoundsWon,
+			Placement: i + 1,
+		})
+	}
+	return standings
+}
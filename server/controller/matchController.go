@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/database"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	MatchesPageSize    = 20
+	MaxMatchesPageSize = 100
+)
+
+type MatchesResponse struct {
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+	Total    int              `json:"total"`
+	Matches  []database.Match `json:"matches"`
+}
+
+// markPlayer marks the player asking in a match's participants
+func markPlayer(match *database.Match, player_id string) {
+	for i := range match.Participants {
+		match.Participants[i].IsUser = match.Participants[i].PlayerId == player_id
+	}
+}
+
+// GetMatchesHandler pages through the player's past matches, newest first
+func GetMatchesHandler(w http.ResponseWriter, r *http.Request) {
+	page, page_size, ok := queryPage(r, MatchesPageSize, MaxMatchesPageSize)
+	if !ok {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+		return
+	}
+
+	response := MatchesResponse{Page: page, PageSize: page_size, Matches: []database.Match{}}
+
+	// Guests don't have a match history
+	player_id := r.Context().Value("player").(string)
+	if isRegistered(player_id) {
+		matches, total, err := database.DB.GetPlayerMatches(player_id, (page - 1) * page_size, page_size)
+		if err != nil {
+			http.Error(w, "failed to get matches", http.StatusInternalServerError)
+			return
+		}
+
+		for i := range matches {
+			markPlayer(&matches[i], player_id)
+		}
+		response.Matches = matches
+		response.Total = total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func GetMatchHandler(w http.ResponseWriter, r *http.Request) {
+	match, err := database.DB.GetMatch(mux.Vars(r)["id"])
+	if err == database.ErrNotFound {
+		http.Error(w, "match not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to get match", http.StatusInternalServerError)
+		return
+	}
+
+	markPlayer(&match, r.Context().Value("player").(string))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
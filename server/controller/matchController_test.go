@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"server/database"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+	uuid "github.com/satori/go.uuid"
+)
+
+// playerRequest is a request as it comes out of the player middleware
+func playerRequest(method string, url string, player_id string, body io.Reader) *http.Request {
+	r := httptest.NewRequest(method, url, body)
+	return r.WithContext(context.WithValue(r.Context(), "player", player_id))
+}
+
+// withStore runs the test against the memory store and, when there is one,
+// redis
+func withStore(t *testing.T, test func(t *testing.T)) {
+	stores := map[string]func(t *testing.T) database.Store{
+		"memory": func(t *testing.T) database.Store { return database.NewMemoryStore() },
+		"redis": func(t *testing.T) database.Store {
+			addr := os.Getenv("REDIS_ADDR")
+			if addr == "" {
+				addr = "localhost:6379"
+			}
+			client := redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("REDIS_PASS"), DB: 15})
+			if err := client.Ping(database.Ctx).Err(); err != nil {
+				t.Skipf("no redis at %s: %v", addr, err)
+			}
+			t.Cleanup(func() { client.Close() })
+			return database.NewRedisStore(client)
+		},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			previous := database.DB
+			database.DB = store(t)
+			defer func() { database.DB = previous }()
+			test(t)
+		})
+	}
+}
+
+func TestMatchIsUser(t *testing.T) {
+	withStore(t, func(t *testing.T) {
+		alice := database.PlayerPrefix + "match-" + uuid.NewV4().String()
+		bob := database.PlayerPrefix + "match-" + uuid.NewV4().String()
+
+		match_id, err := database.DB.SaveMatch(database.Match{
+			GameId: "isuser",
+			EndTime: time.Now(),
+			Participants: []database.MatchParticipant{
+				{PlayerId: alice, Name: "alice", Placement: 1},
+				{PlayerId: bob, Name: "bob", Placement: 2},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Each player sees themselves and only themselves, however the
+		// requests before went
+		for _, player_id := range []string{alice, bob, alice} {
+			w := httptest.NewRecorder()
+			r := mux.SetURLVars(playerRequest("GET", "/matches/" + match_id, player_id, nil), map[string]string{"id": match_id})
+			GetMatchHandler(w, r)
+
+			var match database.Match
+			if err := json.NewDecoder(w.Body).Decode(&match); err != nil {
+				t.Fatal(err)
+			}
+			for _, participant := range match.Participants {
+				want := (participant.Name == "alice") == (player_id == alice)
+				if participant.IsUser != want {
+					t.Errorf("%s sees %s with isUser %v", player_id, participant.Name, participant.IsUser)
+				}
+			}
+		}
+
+		w := httptest.NewRecorder()
+		GetMatchesHandler(w, playerRequest("GET", "/matches", bob, nil))
+		var page MatchesResponse
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Matches) != 1 || !page.Matches[0].Participants[1].IsUser || page.Matches[0].Participants[0].IsUser {
+			t.Errorf("bob's history is %+v", page.Matches)
+		}
+	})
+}
+
+func TestMatchesPage(t *testing.T) {
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"?page=2&pageSize=50", http.StatusOK},
+		{fmt.Sprintf("?page=%d", MaxPage), http.StatusOK},
+		{fmt.Sprintf("?page=%d", MaxPage + 1), http.StatusBadRequest},
+		{"?page=9223372036854775807&pageSize=100", http.StatusBadRequest},
+		{"?page=0", http.StatusBadRequest},
+		{"?page=-1", http.StatusBadRequest},
+		{"?pageSize=1000", http.StatusBadRequest},
+		{"?page=two", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		GetMatchesHandler(w, playerRequest("GET", "/matches" + test.query, database.PlayerPrefix + "pages", nil))
+		if w.Code != test.code {
+			t.Errorf("%q: got %d, want %d", test.query, w.Code, test.code)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"server/database"
+	"server/matchmaking"
+	"sync"
+	"time"
+)
+
+// Players looking for a public game wait in a matchmaking pool until it
+// finds them a game with players of a similar rating. Public games stay
+// open to matchmaking until they start. Ranked games have a pool of their
+// own, matched on competitive rating.
+
+const MatchmakingInterval = 500 * time.Millisecond
+
+// Competitive ratings spread about ten times as wide as speeds
+const RankedScale = 10.0
+
+var Matchmaker = matchmaking.NewPool(MaxPlayersInGame, 1)
+var RankedMatchmaker = matchmaking.NewPool(MaxPlayersInGame, RankedScale)
+
+var (
+	matchmakingOnce sync.Once
+	waitingMutex    sync.Mutex
+	waiting         = make(map[*matchmaking.Ticket]chan string)
+)
+
+func playerRating(player_id string) float64 {
+	stats := database.DB.GetPlayerStats(player_id)
+	speed, _ := stats["AvgSpeed"].(float64)
+	played, _ := stats["MatchesPlayed"].(int)
+	won, _ := stats["MatchesWon"].(int)
+	return matchmaking.Rating(speed, played, won)
+}
+
+func (g *Game) matchmakingPool() *matchmaking.Pool {
+	if g.Ranked {
+		return RankedMatchmaker
+	}
+	return Matchmaker
+}
+
+// findGame puts the player in the pool. The channel gets the id of their
+// game, or an empty id if it couldn't be created.
+func findGame(pool *matchmaking.Pool, player_id string, rating float64) (*matchmaking.Ticket, chan string) {
+	matchmakingOnce.Do(func() { go runMatchmaking() })
+
+	ticket := &matchmaking.Ticket{
+		PlayerId: player_id,
+		Rating: rating,
+		Joined: time.Now(),
+	}
+	found := make(chan string, 1)
+
+	waitingMutex.Lock()
+	waiting[ticket] = found
+	waitingMutex.Unlock()
+
+	pool.Add(ticket)
+	return ticket, found
+}
+
+// stopFinding takes a player who stopped waiting out of the pool
+func stopFinding(pool *matchmaking.Pool, ticket *matchmaking.Ticket) {
+	pool.Remove(ticket)
+
+	waitingMutex.Lock()
+	delete(waiting, ticket)
+	waitingMutex.Unlock()
+}
+
+func runMatchmaking() {
+	ticker := time.NewTicker(MatchmakingInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		stepMatchmaking(Matchmaker, false, now)
+		stepMatchmaking(RankedMatchmaker, true, now)
+	}
+}
+
+func stepMatchmaking(pool *matchmaking.Pool, ranked bool, now time.Time) {
+	for _, match := range pool.Step(now) {
+		game_id := match.GameId
+		if game_id == "" {
+			game_id = startMatch(pool, match, ranked)
+		}
+
+		for _, ticket := range match.Tickets {
+			waitingMutex.Lock()
+			if found, ok := waiting[ticket]; ok {
+				found <- game_id
+				delete(waiting, ticket)
+			}
+			waitingMutex.Unlock()
+		}
+	}
+}
+
+// startMatch creates a public game for a new match and opens it to
+// matchmaking for anyone who fits in later
+func startMatch(pool *matchmaking.Pool, match matchmaking.Match, ranked bool) string {
+	game, err := NewGame(match.Tickets[0].PlayerId, PublicGame, DefaultSettings(PublicGame))
+	if err != nil {
+		return ""
+	}
+
+	if ranked {
+		game.call(func() { game.Ranked = true })
+	}
+
+	database.DB.IncrementGamesCreated()
+	addGame(game)
+	pool.Open(game.Id, match.Ratings, game.MaxPlayers - len(match.Tickets))
+	return game.Id
+}
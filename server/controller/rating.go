@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"server/database"
+	"server/protocol"
+	"server/rating"
+	"strings"
+	"time"
+)
+
+// Registered players in public games have a competitive rating that goes
+// up or down depending on where they place against each other. Ranked games
+// are public games matched on it.
+
+func isRegistered(player_id string) bool {
+	return strings.HasPrefix(player_id, database.PlayerPrefix)
+}
+
+// competitiveRating returns a player's rating, or the default for a player
+// who hasn't played a rated game
+func competitiveRating(player_id string) rating.Rating {
+	current, err := database.DB.GetPlayerRating(player_id)
+	if err != nil || current.Games == 0 {
+		return rating.Default()
+	}
+
+	return rating.Rating{
+		Rating: current.Rating,
+		Deviation: current.Deviation,
+		Volatility: current.Volatility,
+	}
+}
+
+// updateRatings rates the registered players of a public game on where they
+// placed and adds their new rating to the results
+func (g *Game) updateRatings(results []protocol.PlayerResult) {
+	if g.Type != PublicGame {
+		return
+	}
+
+	var players []*Player
+	var ratings []rating.Rating
+	var placements []int
+
+	for i := range g.Players {
+		if isRegistered(g.Players[i].Id) && g.Players[i].Status.Verdict.Clean() {
+			players = append(players, g.Players[i])
+			ratings = append(ratings, competitiveRating(g.Players[i].Id))
+			placements = append(placements, g.Players[i].Status.Placement)
+		}
+	}
+
+	// Nobody to be rated against
+	if len(players) < 2 {
+		return
+	}
+
+	updated := rating.Update(ratings, placements)
+	now := time.Now().Unix()
+
+	for i := range players {
+		change := updated[i].Rating - ratings[i].Rating
+
+		database.DB.UpdatePlayerRating(players[i].Id, database.RatingChange{
+			GameId: strings.Split(g.Id, ":")[1],
+			Rating: updated[i].Rating,
+			Deviation: updated[i].Deviation,
+			Volatility: updated[i].Volatility,
+			Change: change,
+			Placement: placements[i],
+			Time: now,
+		})
+
+		for j := range results {
+			if results[j].Id == players[i].PublicId {
+				results[j].Rating = updated[i].Rating
+				results[j].RatingChange = change
+			}
+		}
+	}
+}
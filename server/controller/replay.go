@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"server/database"
+	"server/text"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Every keystroke a player sends while typing is recorded, whether it was
+// right, wrong or rejected, so the round can be played back afterwards.
+
+type RecordedKey struct {
+	Key    string
+	Offset time.Duration
+	Mark   byte
+}
+
+// recordKey adds a keystroke to the player's recording. Offsets never go
+// back in time, so the replay plays in order.
+func recordKey(player *Player, key string, offset time.Duration, mark byte) {
+	keys := player.Status.Keystrokes
+	if len(keys) >= database.MaxReplayKeystrokes {
+		return
+	}
+
+	if len(keys) > 0 && offset < keys[len(keys) - 1].Offset {
+		offset = keys[len(keys) - 1].Offset
+	}
+
+	player.Status.Keystrokes = append(keys, RecordedKey{Key: key, Offset: offset, Mark: mark})
+}
+
+// recordRejected records a batch the server threw away at the time it
+// arrived
+func recordRejected(player *Player, keys []string, received time.Duration) {
+	for i := range keys {
+		recordKey(player, keys[i], received, database.KeyRejected)
+	}
+}
+
+// track turns a player's recording into its stored form
+func track(player *Player, finished bool) database.ReplayTrack {
+	track := database.ReplayTrack{
+		PlayerId: player.Id,
+		Name: player.Name,
+		Placement: player.Status.Placement,
+		Finished: finished,
+		TypingTime: player.Status.TypingEndTime.Sub(player.Status.TypingStartTime).Milliseconds(),
+		Times: []int64{},
+		Keys: []string{},
+	}
+
+	var previous time.Duration
+	marks := make([]byte, 0, len(player.Status.Keystrokes))
+	for _, key := range player.Status.Keystrokes {
+		track.Times = append(track.Times, (key.Offset - previous).Milliseconds())
+		track.Keys = append(track.Keys, key.Key)
+		marks = append(marks, key.Mark)
+		previous = key.Offset
+	}
+	track.Marks = string(marks)
+
+	return track
+}
+
+func (g *Game) typedTweet(player *Player) bool {
+	return player.Status.CurrentLetterIdx == len(g.TweetGraphemes)
+}
+
+// personalBest returns the player's run of the round, if it can be raced as
+// a ghost later. Ghost games type the tweet with the default text options,
+// so only finished runs typed with them are kept.
+func (g *Game) personalBest(player *Player, track database.ReplayTrack) (database.Run, bool) {
+	if !track.Finished || !isRegistered(player.Id) || g.TextOptions != text.DefaultOptions {
+		return database.Run{}, false
+	}
+
+	return database.Run{
+		TweetId: g.TweetId,
+		TypingMode: g.Settings.TypingMode,
+		Speed: player.Status.Result.NetWPM,
+		Track: track,
+	}, true
+}
+
+// saveReplay keeps the keystrokes of the round that just ended with its
+// match
+func (g *Game) saveReplay(match_id string) {
+	replay := database.Replay{
+		MatchId: match_id,
+		GameId: strings.Split(g.Id, ":")[1],
+		Rematch: g.Rematches,
+		Round: g.Round,
+		TweetId: g.TweetId,
+		Tweet: g.Tweet,
+		TypingMode: g.Settings.TypingMode,
+		Text: g.TextOptions,
+		StartTime: g.RoundStartTime,
+		Duration: time.Since(g.RoundStartTime).Milliseconds(),
+	}
+
+	for _, player := range g.racers() {
+		replay.Tracks = append(replay.Tracks, track(player, g.typedTweet(player)))
+
+		if run, ok := g.personalBest(player, replay.Tracks[len(replay.Tracks) - 1]); ok && player.Status.Verdict.Clean() {
+			database.DB.SavePersonalBest(player.Id, run)
+		}
+	}
+
+	sort.Slice(replay.Tracks, func(i, j int) bool {
+		return replay.Tracks[i].Placement < replay.Tracks[j].Placement
+	})
+	database.DB.SaveReplay(replay)
+}
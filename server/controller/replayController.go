@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/database"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetReplaysHandler returns the replays of a game's rounds, or only the one
+// asked for with ?round=. ?rematch= picks a rematch of the game; without it
+// they are of its first match.
+func GetReplaysHandler(w http.ResponseWriter, r *http.Request) {
+	round, ok := queryInt(r, "round", 0)
+	if !ok {
+		http.Error(w, "invalid round", http.StatusBadRequest)
+		return
+	}
+	// The first match is rematch 0, which queryInt doesn't take
+	rematch := 0
+	if value := r.URL.Query().Get("rematch"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			http.Error(w, "invalid rematch", http.StatusBadRequest)
+			return
+		}
+		rematch = number
+	}
+
+	replays, err := database.DB.GetReplays(mux.Vars(r)["gameId"], rematch)
+	if err == database.ErrNotFound {
+		http.Error(w, "replay not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to get replay", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if round == 0 {
+		json.NewEncoder(w).Encode(replays)
+		return
+	}
+
+	for i := range replays {
+		if replays[i].Round == round {
+			json.NewEncoder(w).Encode(replays[i])
+			return
+		}
+	}
+	http.Error(w, "replay not found", http.StatusNotFound)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/config"
+	"server/database"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	ReviewsPageSize    = 20
+	MaxReviewsPageSize = 100
+)
+
+type ReviewsResponse struct {
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"pageSize"`
+	Total    int                      `json:"total"`
+	Results  []database.FlaggedResult `json:"results"`
+}
+
+type ReviewDecision struct {
+	Approve bool `json:"approve"`
+}
+
+func isReviewer(player_id string) bool {
+	for _, reviewer := range config.Conf.Reviewers {
+		if player_id == database.PlayerPrefix + reviewer {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReviewsHandler pages through the results waiting for review, oldest
+// first
+func GetReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	if !isReviewer(r.Context().Value("player").(string)) {
+		http.Error(w, "only reviewers can see flagged results", http.StatusForbidden)
+		return
+	}
+
+	page, page_size, ok := queryPage(r, ReviewsPageSize, MaxReviewsPageSize)
+	if !ok {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+		return
+	}
+
+	results, total, err := database.DB.GetFlaggedResults((page - 1) * page_size, page_size)
+	if err != nil {
+		http.Error(w, "failed to get flagged results", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReviewsResponse{Page: page, PageSize: page_size, Total: total, Results: results})
+}
+
+// ResolveReviewHandler takes a result off the queue. Approved results are
+// added to the player's stats and personal best; rejected ones are dropped.
+func ResolveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	if !isReviewer(r.Context().Value("player").(string)) {
+		http.Error(w, "only reviewers can resolve flagged results", http.StatusForbidden)
+		return
+	}
+
+	var decision ReviewDecision
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		http.Error(w, "invalid decision", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.ResolveFlaggedResult(mux.Vars(r)["id"])
+	if err == database.ErrNotFound {
+		http.Error(w, "flagged result not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to resolve flagged result", http.StatusInternalServerError)
+		return
+	}
+
+	if decision.Approve {
+		database.DB.PlayerPlayedGame(result.PlayerId, result.Result)
+		if result.Run != nil {
+			database.DB.SavePersonalBest(result.PlayerId, *result.Run)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"server/protocol"
+	"time"
+)
+
+func randomHex(length int) string {
+	value := make([]byte, length)
+	rand.Read(value)
+	return hex.EncodeToString(value)
+}
+
+func newSessionToken() string {
+	return randomHex(16)
+}
+
+// disconnectPlayer keeps a player whose connection dropped in the game for
+// DisconnectGracePeriod so they can resume
+func (g *Game) disconnectPlayer(client *Client) {
+	defer client.close()
+
+	if g.removeSpectator(client) {
+		return
+	}
+
+	player, ok := g.Players[client.PlayerId]
+	if !ok || player.Client != client {
+		return
+	}
+
+	player.Connection = Disconnected
+	player.DisconnectTime = time.Now()
+
+	// Someone else takes over if the host drops, and a ready check no
+	// longer waits on them
+	if g.ensureHost() {
+		g.sendActivePlayers("")
+	}
+	g.checkReady()
+}
+
+// expireDisconnectedPlayers removes players who haven't resumed in time
+func (g *Game) expireDisconnectedPlayers(now time.Time) {
+	expired := false
+	for i := range g.Players {
+		player := g.Players[i]
+		if player.Connection == Disconnected &&
+			now.Sub(player.DisconnectTime) >= DisconnectGracePeriod * time.Second {
+			g.unregisterPlayer(player.Id)
+			expired = true
+		}
+	}
+
+	if expired {
+		g.playersLeft()
+	}
+}
+
+func (g *Game) playerResume(data *protocol.ResumeAction, client *Client) {
+	for i := range g.Players {
+		if subtle.ConstantTimeCompare([]byte(g.Players[i].SessionToken), []byte(data.Token)) == 1 {
+			g.resumePlayer(client, g.Players[i])
+			return
+		}
+	}
+
+	g.sendError(client, protocol.SessionExpired, "Session has expired")
+}
+
+// resumePlayer binds a player to a new connection and sends them everything
+// they need to carry on from where they were
+func (g *Game) resumePlayer(client *Client, player *Player) {
+	// Drop the old connection if it is somehow still open
+	if player.Client != client {
+		player.Client.drop()
+	}
+
+	client.PlayerId = player.Id
+	player.Client = client
+	player.Connection = Connected
+	player.DisconnectTime = time.Time{}
+
+	g.sendSession(player)
+	g.sendSnapshot(player)
+	g.sendActivePlayers(player.Id)
+}
+
+func (g *Game) sendSession(player *Player) {
+	player.Client.sendMessage(&protocol.SendSessionMessage{Token: player.SessionToken})
+}
+
+func (g *Game) sendSnapshot(player *Player) {
+	snapshot := g.snapshot()
+	snapshot.PlayerState = player.Status.State
+	snapshot.Points = player.Status.Points
+	snapshot.CorrectAnswers = player.Status.CorrectAnswers
+	snapshot.IncorrectAnswers = player.Status.IncorrectAnswers
+	snapshot.CurrentLetterIdx = player.Status.CurrentLetterIdx
+	snapshot.Buffer = player.Status.Buffer
+
+	player.Client.sendMessage(&snapshot)
+}
+
+// snapshot is the state of the game without anything about one player
+func (g *Game) snapshot() protocol.SendGameSnapshotMessage {
+	phase, deadline := g.phase()
+
+	snapshot := protocol.SendGameSnapshotMessage{
+		Type: g.Type,
+		State: g.State,
+		Phase: phase,
+		TypingMode: g.Settings.TypingMode,
+		ScoringRule: g.Settings.ScoringRule,
+		Settings: g.Settings,
+		Round: g.Round,
+		Locked: g.Locked,
+	}
+
+	if !deadline.IsZero() {
+		snapshot.Clock = secondsLeft(deadline, time.Now())
+	}
+
+	// The tweet stays hidden until the game starts
+	if g.State != Lobby && g.State != Countdown && g.State != BetweenRounds {
+		snapshot.Tweet = g.Tweet
+		snapshot.Graphemes = g.TweetGraphemes
+		snapshot.AuthorChoices = g.authorChoices()
+	}
+
+	return snapshot
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"encoding/json"
+	"server/protocol"
+	"testing"
+	"time"
+)
+
+// Players who come back on a new connection get their session and where the
+// game is, whatever it is doing, and their old connection is dropped
+func TestReconnect(t *testing.T) {
+	for _, state := range []string{Lobby, Countdown, Started, BetweenRounds, Finished} {
+		game, clients := newTestGame(t, PrivateGame, DefaultSettings(PrivateGame), 2)
+		game.call(func() {
+			game.State = state
+			game.NextRoundTime = time.Now().Add(RoundBreak * time.Second)
+			game.RematchDeadline = time.Now().Add(RematchWindow * time.Second)
+		})
+
+		old := clients[1]
+		again := newFakeClient(old.PlayerId)
+		again.act(game, &protocol.RegisterPlayerAction{Name: "player 1"})
+
+		again.waitFor(t, "sendSession", time.Second)
+		var snapshot protocol.SendGameSnapshotMessage
+		json.Unmarshal(again.waitFor(t, "sendGameSnapshot", time.Second), &snapshot)
+		if snapshot.State != state {
+			t.Errorf("%s: snapshot is of %q", state, snapshot.State)
+		}
+
+		game.call(func() {
+			if game.Players[old.PlayerId].Client != again.Client || !old.closed {
+				t.Errorf("%s: the player is still on the old connection", state)
+			}
+		})
+
+		// Nobody new joins once the game has started
+		if state != Lobby {
+			stranger := newFakeClient("Guest:stranger")
+			stranger.act(game, &protocol.RegisterPlayerAction{Name: "stranger"})
+			var err protocol.Error
+			json.Unmarshal(stranger.waitFor(t, "error", time.Second), &err)
+			if err.Code != protocol.GameAlreadyStarted {
+				t.Errorf("%s: a new player got %+v", state, err)
+			}
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, DefaultSettings(PrivateGame), 3)
+
+	var token string
+	game.call(func() { token = game.Players[clients[1].PlayerId].SessionToken })
+
+	clients[1].act(game, &disconnectMessage{})
+	game.call(func() {
+		if player := game.Players[clients[1].PlayerId]; player.Connection != Disconnected {
+			t.Errorf("a dropped player is %s", player.Connection)
+		}
+	})
+
+	// Guests get a new id on every connection, so the token is what counts
+	again := newFakeClient("Guest:someone-else")
+	again.act(game, &protocol.ResumeAction{Token: token})
+
+	var session protocol.SendSessionMessage
+	json.Unmarshal(again.waitFor(t, "sendSession", time.Second), &session)
+	if session.Token != token {
+		t.Errorf("resumed with token %q, want %q", session.Token, token)
+	}
+	again.waitFor(t, "sendGameSnapshot", time.Second)
+
+	game.call(func() {
+		player := game.Players[clients[1].PlayerId]
+		if player.Client != again.Client || player.Connection != Connected || again.PlayerId != player.Id {
+			t.Errorf("resumed player is %s on %p as %s", player.Connection, player.Client, again.PlayerId)
+		}
+	})
+
+	for _, wrong := range []string{token + "0", token[:len(token) - 1], "nope"} {
+		stranger := newFakeClient("Guest:stranger")
+		stranger.act(game, &protocol.ResumeAction{Token: wrong})
+
+		var err protocol.Error
+		json.Unmarshal(stranger.waitFor(t, "error", time.Second), &err)
+		if err.Code != protocol.SessionExpired {
+			t.Errorf("resuming with %q got %+v", wrong, err)
+		}
+	}
+}
+
+// Players who don't come back within the grace period are removed, and
+// their session with them
+func TestDisconnectGracePeriod(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, DefaultSettings(PrivateGame), 3)
+
+	var token string
+	game.call(func() { token = game.Players[clients[2].PlayerId].SessionToken })
+	clients[2].act(game, &disconnectMessage{})
+
+	grace := DisconnectGracePeriod * time.Second
+	game.call(func() {
+		dropped := game.Players[clients[2].PlayerId].DisconnectTime
+
+		game.expireDisconnectedPlayers(dropped.Add(grace - time.Second))
+		if _, ok := game.Players[clients[2].PlayerId]; !ok {
+			t.Error("removed before the grace period was up")
+		}
+
+		game.expireDisconnectedPlayers(dropped.Add(grace))
+		if _, ok := game.Players[clients[2].PlayerId]; ok {
+			t.Error("still in the game after the grace period")
+		}
+		if len(game.Players) != 2 {
+			t.Errorf("%d players left, want 2", len(game.Players))
+		}
+	})
+
+	late := newFakeClient("Guest:late")
+	late.act(game, &protocol.ResumeAction{Token: token})
+	var err protocol.Error
+	json.Unmarshal(late.waitFor(t, "error", time.Second), &err)
+	if err.Code != protocol.SessionExpired {
+		t.Errorf("resuming after the grace period got %+v", err)
+	}
+}
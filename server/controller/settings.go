@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"fmt"
+	"server/protocol"
+	"server/scoring"
+	"server/text"
+)
+
+// Limits on what a host can choose for a private game
+const (
+	MinTimeLimit      = 10
+	MaxTimeLimit      = 300
+	MinGuessTimeLimit = 5
+	MaxGuessTimeLimit = 60
+	MinCountdown      = 3
+	MaxCountdown      = 60
+	MinAuthorChoices  = 2
+	MaxAuthorChoices  = 8
+	AuthorChoiceCount = 4
+	MaxRounds         = 10
+)
+
+func DefaultSettings(game_type string) protocol.GameSettings {
+	countdown := PrivateGameCountdown
+	if game_type == PublicGame {
+		countdown = PublicGameCountdown
+	}
+	text_options := text.DefaultOptions
+
+	return protocol.GameSettings{
+		MaxPlayers: MaxPlayersInGame,
+		TimeLimit: RoundTimeLimit,
+		GuessTimeLimit: GuessTimeLimit,
+		Countdown: countdown,
+		AuthorChoices: AuthorChoiceCount,
+		Categories: []string{},
+		GuessingEnabled: true,
+		TypingMode: StrictTyping,
+		ScoringRule: scoring.DefaultRule,
+		Rounds: 1,
+		Text: &text_options,
+	}
+}
+
+// textOptions returns how the settings normalize tweets and keys
+func textOptions(settings protocol.GameSettings) text.Options {
+	if settings.Text == nil {
+		return text.DefaultOptions
+	}
+	return *settings.Text
+}
+
+// validateSettings checks settings chosen by a host. The error is meant to
+// be shown to them.
+func validateSettings(settings protocol.GameSettings) error {
+	switch {
+	case settings.MaxPlayers < 1 || settings.MaxPlayers > MaxPlayersInGame:
+		return fmt.Errorf("maxPlayers must be between 1 and %d", MaxPlayersInGame)
+	case settings.TimeLimit < MinTimeLimit || settings.TimeLimit > MaxTimeLimit:
+		return fmt.Errorf("timeLimit must be between %d and %d", MinTimeLimit, MaxTimeLimit)
+	case settings.GuessTimeLimit < MinGuessTimeLimit || settings.GuessTimeLimit > MaxGuessTimeLimit:
+		return fmt.Errorf("guessTimeLimit must be between %d and %d", MinGuessTimeLimit, MaxGuessTimeLimit)
+	case settings.Countdown < MinCountdown || settings.Countdown > MaxCountdown:
+		return fmt.Errorf("countdown must be between %d and %d", MinCountdown, MaxCountdown)
+	case settings.AuthorChoices < MinAuthorChoices || settings.AuthorChoices > MaxAuthorChoices:
+		return fmt.Errorf("authorChoices must be between %d and %d", MinAuthorChoices, MaxAuthorChoices)
+	case settings.MinTweetLength < 0 || settings.MaxTweetLength < 0:
+		return fmt.Errorf("tweet lengths can't be negative")
+	case settings.MaxTweetLength > 0 && settings.MinTweetLength > settings.MaxTweetLength:
+		return fmt.Errorf("minTweetLength can't be more than maxTweetLength")
+	case settings.Rounds < 1 || settings.Rounds > MaxRounds:
+		return fmt.Errorf("rounds must be between 1 and %d", MaxRounds)
+	case settings.TypingMode != StrictTyping && settings.TypingMode != RealTyping:
+		return fmt.Errorf("typingMode must be %s or %s", StrictTyping, RealTyping)
+	}
+
+	if _, ok := scoring.Rule(settings.ScoringRule); !ok {
+		return fmt.Errorf("scoringRule must be one of %v", scoring.RuleNames())
+	}
+
+	for i := range settings.Categories {
+		if _, ok := AuthorCategories[settings.Categories[i]]; !ok {
+			return fmt.Errorf("unknown category %q", settings.Categories[i])
+		}
+	}
+
+	// Tweet lengths depend on the text options, since skipped emoji
+	// aren't typed
+	if len(matchingTweets(settings)) == 0 {
+		return fmt.Errorf("no tweets match these settings")
+	}
+
+	return nil
+}
+
+// applySettings sets up the game for new settings and picks a tweet that
+// matches them
+func (g *Game) applySettings(settings protocol.GameSettings) {
+	if settings.Categories == nil {
+		settings.Categories = []string{}
+	}
+
+	rule, ok := scoring.Rule(settings.ScoringRule)
+	if !ok {
+		rule = scoring.Classic{}
+	}
+
+	// The game keeps its own copy of the text options
+	text_options := textOptions(settings)
+	settings.Text = &text_options
+
+	g.Settings = settings
+	g.TextOptions = text_options
+	g.ScoringRule = rule
+	g.MaxPlayers = settings.MaxPlayers
+	g.TimeLimit = settings.TimeLimit
+	g.newTweet()
+}
+
+func (g *Game) updateSettings(data *protocol.UpdateSettingsAction, client *Client, player_id string) {
+	if !g.isHost(player_id) {
+		g.sendError(client, protocol.NotHost, "Only the host can change the settings")
+		return
+	}
+
+	if err := validateSettings(data.Settings); err != nil {
+		g.sendError(client, protocol.InvalidSettings, err.Error())
+		return
+	}
+
+	if data.Settings.MaxPlayers < len(g.Players) {
+		g.sendError(client, protocol.InvalidSettings, "maxPlayers is less than the players in the game")
+		return
+	}
+
+	// Everyone has to agree to the new settings
+	g.resetReady()
+	g.applySettings(data.Settings)
+	g.broadcastMessage((*protocol.SendSettingsMessage)(&g.Settings))
+}
+
+// authorChoices returns the authors to guess from, or none when the game has
+// no guessing
+func (g *Game) authorChoices() []string {
+	if !g.Settings.GuessingEnabled {
+		return []string{}
+	}
+	return g.AuthorChoices
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"server/protocol"
+	"server/text"
+	"testing"
+)
+
+func TestTextOptionsSettings(t *testing.T) {
+	settings := DefaultSettings(PrivateGame)
+	if textOptions(settings) != text.DefaultOptions {
+		t.Errorf("default settings use %+v", textOptions(settings))
+	}
+
+	// Clients that don't know about text options leave them out
+	settings.Text = nil
+	if err := validateSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	options := text.Options{SmartQuotes: false, FoldAccents: true, SkipEmoji: false}
+	settings.Text = &options
+	if err := validateSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &Game{PlayedTweets: make(map[string]bool)}
+	g.applySettings(settings)
+	options.FoldAccents = false
+
+	if g.TextOptions != (text.Options{FoldAccents: true}) || *g.Settings.Text != g.TextOptions {
+		t.Errorf("game has %+v, settings %+v", g.TextOptions, *g.Settings.Text)
+	}
+	if len(g.TweetGraphemes) == 0 {
+		t.Error("no tweet was picked")
+	}
+
+	// Lengths are of the tweet as it is typed with the options
+	lengths := tweetLengths(g.TextOptions)
+	for i := range *Tweets {
+		if want := len(text.Prepare((*Tweets)[i].Content, g.TextOptions)); lengths[i] != want {
+			t.Fatalf("tweet %d has length %d, want %d", i, lengths[i], want)
+		}
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *protocol.GameSettings)
+		valid  bool
+	}{
+		{"defaults", func(s *protocol.GameSettings) {}, true},
+
+		{"fewest players", func(s *protocol.GameSettings) { s.MaxPlayers = 1 }, true},
+		{"no players", func(s *protocol.GameSettings) { s.MaxPlayers = 0 }, false},
+		{"most players", func(s *protocol.GameSettings) { s.MaxPlayers = MaxPlayersInGame }, true},
+		{"too many players", func(s *protocol.GameSettings) { s.MaxPlayers = MaxPlayersInGame + 1 }, false},
+
+		{"shortest time limit", func(s *protocol.GameSettings) { s.TimeLimit = MinTimeLimit }, true},
+		{"time limit too short", func(s *protocol.GameSettings) { s.TimeLimit = MinTimeLimit - 1 }, false},
+		{"longest time limit", func(s *protocol.GameSettings) { s.TimeLimit = MaxTimeLimit }, true},
+		{"time limit too long", func(s *protocol.GameSettings) { s.TimeLimit = MaxTimeLimit + 1 }, false},
+
+		{"shortest guess time", func(s *protocol.GameSettings) { s.GuessTimeLimit = MinGuessTimeLimit }, true},
+		{"guess time too short", func(s *protocol.GameSettings) { s.GuessTimeLimit = MinGuessTimeLimit - 1 }, false},
+		{"longest guess time", func(s *protocol.GameSettings) { s.GuessTimeLimit = MaxGuessTimeLimit }, true},
+		{"guess time too long", func(s *protocol.GameSettings) { s.GuessTimeLimit = MaxGuessTimeLimit + 1 }, false},
+
+		{"shortest countdown", func(s *protocol.GameSettings) { s.Countdown = MinCountdown }, true},
+		{"countdown too short", func(s *protocol.GameSettings) { s.Countdown = MinCountdown - 1 }, false},
+		{"longest countdown", func(s *protocol.GameSettings) { s.Countdown = MaxCountdown }, true},
+		{"countdown too long", func(s *protocol.GameSettings) { s.Countdown = MaxCountdown + 1 }, false},
+
+		{"fewest author choices", func(s *protocol.GameSettings) { s.AuthorChoices = MinAuthorChoices }, true},
+		{"too few author choices", func(s *protocol.GameSettings) { s.AuthorChoices = MinAuthorChoices - 1 }, false},
+		{"most author choices", func(s *protocol.GameSettings) { s.AuthorChoices = MaxAuthorChoices }, true},
+		{"too many author choices", func(s *protocol.GameSettings) { s.AuthorChoices = MaxAuthorChoices + 1 }, false},
+
+		{"one round", func(s *protocol.GameSettings) { s.Rounds = 1 }, true},
+		{"no rounds", func(s *protocol.GameSettings) { s.Rounds = 0 }, false},
+		{"most rounds", func(s *protocol.GameSettings) { s.Rounds = MaxRounds }, true},
+		{"too many rounds", func(s *protocol.GameSettings) { s.Rounds = MaxRounds + 1 }, false},
+
+		{"negative length", func(s *protocol.GameSettings) { s.MinTweetLength = -1 }, false},
+		{"lengths the wrong way round", func(s *protocol.GameSettings) { s.MinTweetLength = 100; s.MaxTweetLength = 50 }, false},
+		{"no tweets that short", func(s *protocol.GameSettings) { s.MaxTweetLength = 1 }, false},
+		{"no length limit", func(s *protocol.GameSettings) { s.MinTweetLength = 0; s.MaxTweetLength = 0 }, true},
+
+		{"unknown typing mode", func(s *protocol.GameSettings) { s.TypingMode = "blind" }, false},
+		{"unknown scoring rule", func(s *protocol.GameSettings) { s.ScoringRule = "golf" }, false},
+		{"unknown category", func(s *protocol.GameSettings) { s.Categories = []string{"not a category"} }, false},
+	}
+
+	for _, test := range tests {
+		settings := DefaultSettings(PrivateGame)
+		test.change(&settings)
+		if err := validateSettings(settings); (err == nil) != test.valid {
+			t.Errorf("%s: got %v, want valid %v", test.name, err, test.valid)
+		}
+	}
+}
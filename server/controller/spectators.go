@@ -0,0 +1,109 @@
+package controller
+
+import "server/protocol"
+
+// Spectators watch a game without playing in it. They are sent everything
+// that is broadcast to the players but can't type or guess, and don't take
+// up a player slot. A spectator can still join as a player from the lobby.
+
+const MaxSpectators = 50
+
+type Spectator struct {
+	Id       string
+	PublicId string
+	Name     string
+	Client   *Client
+}
+
+func (g *Game) registerSpectator(data *protocol.SpectateAction, client *Client, player_id string) {
+	if _, ok := g.Players[player_id]; ok {
+		g.sendError(client, protocol.InvalidData, "You are already playing in this game")
+		return
+	}
+
+	if g.Kicked[player_id] {
+		g.sendError(client, protocol.Kicked, "You were removed from the game by the host")
+		return
+	}
+
+	if spectator, ok := g.Spectators[player_id]; ok {
+		// Watching again on a new connection
+		if spectator.Client != client {
+			spectator.Client.close()
+		}
+		spectator.Client = client
+	} else {
+		if len(g.Spectators) == MaxSpectators {
+			g.sendError(client, protocol.GameFull, "Too many spectators")
+			return
+		}
+
+		g.Spectators[player_id] = &Spectator{
+			Id: player_id,
+			PublicId: randomHex(4),
+			Name: data.Name,
+			Client: client,
+		}
+	}
+
+	client.sendMessage(protocol.SendGameTypeMessage(g.Type))
+	client.sendMessage((*protocol.SendSettingsMessage)(&g.Settings))
+
+	snapshot := g.snapshot()
+	snapshot.Spectating = true
+	client.sendMessage(&snapshot)
+
+	g.sendActivePlayers("")
+}
+
+// removeSpectator drops a spectator whose connection has gone
+func (g *Game) removeSpectator(client *Client) bool {
+	spectator, ok := g.Spectators[client.PlayerId]
+	if !ok || spectator.Client != client {
+		return false
+	}
+
+	delete(g.Spectators, client.PlayerId)
+	g.sendActivePlayers("")
+	return true
+}
+
+func (g *Game) isSpectator(player_id string) bool {
+	_, ok := g.Spectators[player_id]
+	return ok
+}
+
+// spectatorAllowed reports whether a spectator may send the message
+func spectatorAllowed(message protocol.ClientMessage) bool {
+	switch message.(type) {
+	case *disconnectMessage, *protocol.PingAction,
+		*protocol.SpectateAction, *protocol.RegisterPlayerAction:
+		return true
+	}
+	return false
+}
+
+// sendSpectators sends everyone the list of spectators
+func (g *Game) sendSpectators() {
+	spectators := protocol.SendSpectatorsMessage{}
+
+	for i := range g.Spectators {
+		spectators = append(spectators, protocol.SpectatorInfo{
+			Id: g.Spectators[i].PublicId,
+			Name: g.Spectators[i].Name,
+		})
+	}
+
+	for i := range g.Players {
+		g.Players[i].Client.sendMessage(spectators)
+	}
+
+	for i := range g.Spectators {
+		message := make(protocol.SendSpectatorsMessage, len(spectators))
+		for j := range spectators {
+			message[j] = spectators[j]
+			message[j].IsUser = spectators[j].Id == g.Spectators[i].PublicId
+		}
+		g.Spectators[i].Client.sendMessage(message)
+	}
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"encoding/json"
+	"server/database"
+	"server/protocol"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestSpectator(t *testing.T) {
+	game, clients := newTestGame(t, PrivateGame, testSettings(), 2)
+
+	watcher_id, err := database.DB.CreatePlayer("test-" + uuid.NewV4().String(), "watcher", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher := newFakeClient(watcher_id)
+	watcher.act(game, &protocol.SpectateAction{Name: "watcher"})
+
+	var snapshot protocol.SendGameSnapshotMessage
+	json.Unmarshal(watcher.waitFor(t, "sendGameSnapshot", time.Second), &snapshot)
+	if !snapshot.Spectating {
+		t.Error("the spectator's snapshot isn't marked spectating")
+	}
+
+	var spectators protocol.SendSpectatorsMessage
+	json.Unmarshal(watcher.waitFor(t, "sendSpectators", time.Second), &spectators)
+	if len(spectators) != 1 || spectators[0].Name != "watcher" || !spectators[0].IsUser {
+		t.Errorf("the spectator sees spectators %+v", spectators)
+	}
+
+	// Spectators can only watch
+	for _, message := range []protocol.ClientMessage{
+		&protocol.StartCountdownAction{},
+		&protocol.ReadyAction{Ready: true},
+		&protocol.PlayerMoveAction{Key: "a"},
+		&protocol.PlayerGuessAction{Guess: "someone"},
+		&protocol.UpdateSettingsAction{Settings: testSettings()},
+	} {
+		watcher.act(game, message)
+		expectError(t, watcher, protocol.Spectating)
+	}
+
+	finish, _ := playRound(t, game, clients, nil)
+	watcher.waitFor(t, "startGame", time.Second)
+	watcher.waitFor(t, "startFinish", time.Second)
+
+	// and aren't in the results or the match
+	if len(finish.Results) != len(clients) {
+		t.Errorf("%d results for %d players", len(finish.Results), len(clients))
+	}
+	game.call(func() {
+		if _, ok := game.Players[watcher_id]; ok {
+			t.Error("the spectator became a player")
+		}
+	})
+	if matches, _, _ := database.DB.GetPlayerMatches(watcher_id, 0, 10); len(matches) != 0 {
+		t.Errorf("the spectator has matches %+v", matches)
+	}
+	if stats := database.DB.GetPlayerStats(watcher_id); stats["MatchesPlayed"] != 0 {
+		t.Errorf("the spectator has stats %v", stats)
+	}
+}
@@ -4,20 +4,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"math/rand"
 	"os"
+	"server/protocol"
+	"server/text"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
 var Tweets *[]Tweet = &[]Tweet{}
 var TweetAuthors *[]TweetAuthor = &[]TweetAuthor{}
 
+// The categories authors are sorted into
+var AuthorCategories = make(map[string]bool)
+var authorCategory = make(map[string]string)
+
 type TweetAuthor struct {
 	Name     string `json:"name"`
 	UserId   int    `json:"user_id"`
 	Username string `json:"username"`
+	Category string `json:"category"`
 }
 
 type Tweet struct {
@@ -27,24 +35,93 @@ type Tweet struct {
 	Content        string `json:"tweet_text"`
 }
 
-func generateTweet() (string, string, int, string, string, []string) {
+// matchingTweets returns the index of every tweet the settings allow
+func matchingTweets(settings protocol.GameSettings) []int {
+	var result []int
+	lengths := tweetLengths(textOptions(settings))
+
+	for i := range *Tweets {
+		length := lengths[i]
+		if length < settings.MinTweetLength || (settings.MaxTweetLength > 0 && length > settings.MaxTweetLength) {
+			continue
+		}
+		if len(settings.Categories) > 0 && !inCategories((*Tweets)[i].AuthorUsername, settings.Categories) {
+			continue
+		}
+		result = append(result, i)
+	}
+
+	return result
+}
+
+// Length of each tweet in graphemes once normalized, for each set of text
+// options games have asked for
+var tweetLengthsCache = make(map[text.Options][]int)
+var tweetLengthsMutex sync.Mutex
+
+func tweetLengths(options text.Options) []int {
+	tweetLengthsMutex.Lock()
+	defer tweetLengthsMutex.Unlock()
+
+	if lengths, ok := tweetLengthsCache[options]; ok {
+		return lengths
+	}
+
+	lengths := make([]int, len(*Tweets))
+	for i := range *Tweets {
+		lengths[i] = len(text.Prepare((*Tweets)[i].Content, options))
+	}
+	tweetLengthsCache[options] = lengths
+	return lengths
+}
+
+func inCategories(username string, categories []string) bool {
+	for i := range categories {
+		if authorCategory[username] == categories[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func generateTweet(settings protocol.GameSettings) (string, string, int, string, string, []string) {
 	rand.Seed(time.Now().UnixNano())
-	tweet_count := len(*Tweets)
-	tweet_number := rand.Intn(tweet_count)
+
+	candidates := matchingTweets(settings)
+	if len(candidates) == 0 {
+		for i := range *Tweets {
+			candidates = append(candidates, i)
+		}
+	}
+
+	return loadTweet(candidates[rand.Intn(len(candidates))], settings)
+}
+
+// loadTweet returns a tweet by its number along with authors to guess from
+func loadTweet(tweet_number int, settings protocol.GameSettings) (string, string, int, string, string, []string) {
 	tweet := (*Tweets)[tweet_number]
 	author_choices := []string{tweet.AuthorName}
+	chosen := map[string]bool{tweet.AuthorName: true}
+
+	// Other authors from the same categories come first so the guess isn't
+	// given away by the topic
+	order := rand.Perm(len(*TweetAuthors))
+	sort.SliceStable(order, func(i, j int) bool {
+		return inCategories((*TweetAuthors)[order[i]].Username, settings.Categories) &&
+			!inCategories((*TweetAuthors)[order[j]].Username, settings.Categories)
+	})
+
+	for _, author_number := range order {
+		if len(author_choices) >= settings.AuthorChoices {
+			break
+		}
 
-	for {
-		author_count := len(*TweetAuthors)
-		author_number := rand.Intn(author_count)
 		author := (*TweetAuthors)[author_number]
-		if author.Username == tweet.AuthorUsername {
+		if author.Username == tweet.AuthorUsername || chosen[author.Name] {
 			continue
 		}
 		author_choices = append(author_choices, author.Name)
-		if len(author_choices) == 4 {
-			break
-		}
+		chosen[author.Name] = true
 	}
 
 	// Shuffle the author choices
@@ -60,30 +137,52 @@ func generateTweet() (string, string, int, string, string, []string) {
 	return id, tweet_content, words, author, author_handle, author_choices
 }
 
-func init() {
+// tweetNumber returns the number of the tweet with the given id
+func tweetNumber(tweet_id string) (int, bool) {
+	var tweet_number int
+	if _, err := fmt.Sscanf(tweet_id, "TweetPrefix:%d", &tweet_number); err != nil {
+		return 0, false
+	}
+	return tweet_number, tweet_number >= 0 && tweet_number < len(*Tweets)
+}
+
+// LoadTweets reads the tweet authors and tweets. The server loads them from
+// its working directory on start.
+func LoadTweets(users_path string, tweets_path string) error {
 	// Load twitter users data
-	tweet_authors_json, err := os.Open("users.json")
+	tweet_authors_json, err := os.Open(users_path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer tweet_authors_json.Close()
 	
 	tweet_authors_byte, _ := ioutil.ReadAll(tweet_authors_json)
 	if err := json.Unmarshal(tweet_authors_byte, TweetAuthors); err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// Load tweets data
-	tweets_json, err := os.Open("tweets.json")
+	tweets_json, err := os.Open(tweets_path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer tweets_json.Close()
 
 	tweets_json_byte, _ := ioutil.ReadAll(tweets_json)
 	if err := json.Unmarshal(tweets_json_byte, Tweets); err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	for i := range *TweetAuthors {
+		authorCategory[(*TweetAuthors)[i].Username] = (*TweetAuthors)[i].Category
+		AuthorCategories[(*TweetAuthors)[i].Category] = true
 	}
 
+	tweetLengthsMutex.Lock()
+	tweetLengthsCache = make(map[text.Options][]int)
+	tweetLengthsMutex.Unlock()
+	tweetLengths(text.DefaultOptions)
+
 	fmt.Println("Finished loading tweet json files")
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"server/protocol"
+	"server/text"
+	"sort"
+	"strings"
+)
+
+// In strict typing a wrong key is rejected and the cursor stays put. In real
+// typing wrong keys land in the player's buffer and have to be deleted with
+// Backspace, the way most typing tests work. Errors are counted per word
+// either way.
+
+func (g *Game) applyStrictKey(player *Player, key string) {
+	for _, typed := range text.Keys(key, g.TextOptions) {
+		idx := player.Status.CurrentLetterIdx
+		if idx == len(g.TweetGraphemes) {
+			break
+		}
+
+		if text.Match(g.TweetGraphemes[idx], typed, g.TextOptions) {
+			player.Status.CurrentLetterIdx += 1
+			player.Status.CorrectAnswers += 1
+		} else {
+			// The mistake never makes it into the text
+			g.countError(player, idx)
+			player.Status.CorrectedErrors += 1
+		}
+	}
+}
+
+func (g *Game) applyRealKey(player *Player, key string) {
+	if key == BackspaceKey {
+		g.deleteKey(player)
+		return
+	}
+
+	for _, typed := range text.Keys(key, g.TextOptions) {
+		idx := player.Status.CurrentLetterIdx
+		position := idx + len(player.Status.Buffer)
+
+		// Nothing can be typed past the end of the tweet
+		if position == len(g.TweetGraphemes) {
+			break
+		}
+
+		matches := text.Match(g.TweetGraphemes[position], typed, g.TextOptions)
+
+		if matches && len(player.Status.Buffer) == 0 {
+			player.Status.CurrentLetterIdx += 1
+			player.Status.CorrectAnswers += 1
+			continue
+		}
+
+		if len(player.Status.Buffer) == MaxErrorBuffer {
+			break
+		}
+
+		// Right keys typed after a mistake still have to be deleted, but
+		// only wrong ones count as errors
+		player.Status.Buffer = append(player.Status.Buffer, typed)
+		if !matches {
+			g.countError(player, position)
+		}
+	}
+}
+
+// deleteKey removes the last grapheme from the player's buffer. Text typed
+// correctly before the first mistake is kept.
+func (g *Game) deleteKey(player *Player) {
+	length := len(player.Status.Buffer)
+	if length == 0 {
+		return
+	}
+
+	position := player.Status.CurrentLetterIdx + length - 1
+	if !text.Match(g.TweetGraphemes[position], player.Status.Buffer[length - 1], g.TextOptions) {
+		player.Status.CorrectedErrors += 1
+	}
+
+	player.Status.Buffer = player.Status.Buffer[:length - 1]
+}
+
+func (g *Game) countError(player *Player, position int) {
+	player.Status.IncorrectAnswers += 1
+	player.Status.WordErrors[wordAt(g.TweetGraphemes, position)] += 1
+}
+
+// bufferErrors counts the mistakes still left in the player's buffer
+func (g *Game) bufferErrors(player *Player) int {
+	var count int = 0
+	for i := range player.Status.Buffer {
+		position := player.Status.CurrentLetterIdx + i
+		if !text.Match(g.TweetGraphemes[position], player.Status.Buffer[i], g.TextOptions) {
+			count += 1
+		}
+	}
+	return count
+}
+
+// wordAt returns which word of the tweet a grapheme belongs to. Spaces count
+// towards the word before them.
+func wordAt(graphemes []string, position int) int {
+	var word int = 0
+	for i := 0; i < position && i < len(graphemes); i++ {
+		if graphemes[i] == " " {
+			word += 1
+		}
+	}
+	return word
+}
+
+// wordErrors lists the words a player made mistakes in, in tweet order
+func (g *Game) wordErrors(player *Player) []protocol.WordError {
+	words := strings.Split(g.Tweet, " ")
+	result := []protocol.WordError{}
+
+	for word, errors := range player.Status.WordErrors {
+		if word < len(words) {
+			result = append(result, protocol.WordError{Word: word, Text: words[word], Errors: errors})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Word < result[j].Word })
+	return result
+}
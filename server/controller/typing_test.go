@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"reflect"
+	"server/protocol"
+	"server/text"
+	"testing"
+)
+
+func TestWordErrors(t *testing.T) {
+	g := &Game{TextOptions: text.DefaultOptions}
+	g.Settings.TypingMode = RealTyping
+	g.setTweet("TweetPrefix:0", "big red  dog", 3, "", "", nil)
+
+	player := NewPlayer("Player:words", "words", true, nil, Keyboards[0])
+	player.Status.State = Typing
+
+	// A wrong key in "big" pushes the i after it out of place, so both are
+	// mistakes and both are deleted. Two wrong keys in "dog" are left in.
+	for _, key := range []string{"b", "x", "i", BackspaceKey, BackspaceKey, "i", "g", " ", "r", "e", "d", " ", "d", "q", "z"} {
+		g.applyKey(player, key, 0)
+	}
+	g.Players = map[string]*Player{player.Id: player}
+	player.Status.UncorrectedErrors = g.bufferErrors(player)
+
+	want := []protocol.WordError{{Word: 0, Text: "big", Errors: 2}, {Word: 2, Text: "dog", Errors: 2}}
+	if got := g.wordErrors(player); !reflect.DeepEqual(got, want) {
+		t.Errorf("wordErrors = %+v, want %+v", got, want)
+	}
+	if player.Status.CorrectedErrors != 2 || player.Status.UncorrectedErrors != 2 {
+		t.Errorf("corrected %d, uncorrected %d", player.Status.CorrectedErrors, player.Status.UncorrectedErrors)
+	}
+
+	if got := g.wordErrors(NewPlayer("Player:clean", "clean", false, nil, Keyboards[0])); len(got) != 0 || got == nil {
+		t.Errorf("a clean player has %#v", got)
+	}
+}
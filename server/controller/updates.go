@@ -0,0 +1,88 @@
+package controller
+
+import "server/protocol"
+
+// Player progress is sent as deltas once per tick rather than as the full
+// roster on every keystroke, so a busy game costs one encode per tick.
+
+func (g *Game) playerInfo(player *Player) protocol.PlayerInfo {
+	return protocol.PlayerInfo{
+		Id: player.PublicId,
+		Name: player.Name,
+		IsCreator: player.Creator,
+		Speed: player.Status.Speed,
+		State: player.Status.State,
+		Points: player.Status.Points,
+		Placement: player.Status.Placement,
+		CorrectAnswers: player.Status.CorrectAnswers,
+		KeyboardLink: player.Keyboard.ClientImageLink,
+		IncorrectAnswers: player.Status.IncorrectAnswers,
+		CurrentLetterIdx: player.Status.CurrentLetterIdx,
+		Connection: player.Connection,
+		Ready: player.Ready,
+		Ghost: player.Ghost,
+	}
+}
+
+// diffPlayerInfo returns the fields that changed between two views of a
+// player and whether there were any
+func diffPlayerInfo(prev protocol.PlayerInfo, next protocol.PlayerInfo) (protocol.PlayerDelta, bool) {
+	delta := protocol.PlayerDelta{Id: next.Id}
+	changed := false
+
+	if prev.Speed != next.Speed {
+		delta.Speed = &next.Speed
+		changed = true
+	}
+	if prev.Points != next.Points {
+		delta.Points = &next.Points
+		changed = true
+	}
+	if prev.State != next.State {
+		delta.State = &next.State
+		changed = true
+	}
+	if prev.Placement != next.Placement {
+		delta.Placement = &next.Placement
+		changed = true
+	}
+	if prev.CorrectAnswers != next.CorrectAnswers {
+		delta.CorrectAnswers = &next.CorrectAnswers
+		changed = true
+	}
+	if prev.IncorrectAnswers != next.IncorrectAnswers {
+		delta.IncorrectAnswers = &next.IncorrectAnswers
+		changed = true
+	}
+	if prev.CurrentLetterIdx != next.CurrentLetterIdx {
+		delta.CurrentLetterIdx = &next.CurrentLetterIdx
+		changed = true
+	}
+	if prev.Connection != next.Connection {
+		delta.Connection = &next.Connection
+		changed = true
+	}
+	if prev.Ready != next.Ready {
+		delta.Ready = &next.Ready
+		changed = true
+	}
+
+	return delta, changed
+}
+
+// sendPlayerUpdates broadcasts whatever changed since the last update
+func (g *Game) sendPlayerUpdates() {
+	var updates protocol.PlayerUpdatesMessage
+
+	for _, player := range g.racers() {
+		info := g.playerInfo(player)
+		if delta, changed := diffPlayerInfo(player.lastSent, info); changed {
+			updates = append(updates, delta)
+			player.lastSent = info
+		}
+	}
+
+	if len(updates) > 0 {
+		g.broadcastMessage(updates)
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// A full game: six players at 120 WPM press ten keys a second each
+const (
+	benchmarkPlayers     = 6
+	benchmarkKeysPerTick = 1
+	benchmarkTicks       = 10
+)
+
+// benchmarkGame sets up a started game whose clients only count what is
+// sent to them. stop closes the clients and returns the bytes sent.
+func benchmarkGame() (g *Game, order []*Player, stop func() int64) {
+	g = &Game{
+		State: Started,
+		Players: make(map[string]*Player),
+		Spectators: make(map[string]*Spectator),
+		Ghosts: make(map[string]*Ghost),
+	}
+
+	var sent int64
+	var drained sync.WaitGroup
+	for i := 0; i < benchmarkPlayers; i++ {
+		client := &Client{PlayerId: fmt.Sprintf("Player:bench-%d", i), send: make(chan []byte, 1 << 16)}
+		drained.Add(1)
+		go func() {
+			defer drained.Done()
+			for message := range client.send {
+				atomic.AddInt64(&sent, int64(len(message)))
+			}
+		}()
+
+		player := NewPlayer(client.PlayerId, fmt.Sprintf("bench %d", i), i == 0, client, Keyboards[0])
+		player.Status.State = Typing
+		g.Players[player.Id] = player
+		order = append(order, player)
+	}
+	g.sendActivePlayers("")
+
+	stop = func() int64 {
+		for _, player := range order {
+			player.Client.close()
+		}
+		drained.Wait()
+		return sent
+	}
+	return g, order, stop
+}
+
+// typeKey moves a player on by one correct key
+func typeKey(player *Player) {
+	player.Status.CurrentLetterIdx += 1
+	player.Status.CorrectAnswers += 1
+	player.Status.Speed = float64(player.Status.CurrentLetterIdx % 130)
+}
+
+// BenchmarkPlayerUpdates measures one second of the game above. Ticks sends
+// the changes once per tick; RosterPerKeystroke is how progress used to be
+// sent, the full roster to every player on every key.
+func BenchmarkPlayerUpdates(b *testing.B) {
+	b.Run("Ticks", func(b *testing.B) {
+		g, order, stop := benchmarkGame()
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			for tick := 0; tick < benchmarkTicks; tick++ {
+				for _, player := range order {
+					for k := 0; k < benchmarkKeysPerTick; k++ {
+						typeKey(player)
+					}
+				}
+				g.sendPlayerUpdates()
+			}
+		}
+
+		b.StopTimer()
+		b.ReportMetric(float64(stop()) / float64(b.N), "sent-B/op")
+	})
+
+	b.Run("RosterPerKeystroke", func(b *testing.B) {
+		g, order, stop := benchmarkGame()
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			for key := 0; key < benchmarkTicks * benchmarkKeysPerTick * benchmarkPlayers; key++ {
+				typeKey(order[key % benchmarkPlayers])
+				g.sendActivePlayers("")
+			}
+		}
+
+		b.StopTimer()
+		b.ReportMetric(float64(stop()) / float64(b.N), "sent-B/op")
+	})
+}
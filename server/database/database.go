@@ -6,15 +6,24 @@ import (
 	"server/config"
 
 	"github.com/go-redis/redis/v8"
-	dotenv "github.com/joho/godotenv"
 )
 
 var Ctx = context.Background()
-var RedisClient *redis.Client
 
-func init() {
-	if err := dotenv.Load(".env"); err != nil {
-		log.Fatal("failed to connect to redis")
+// DB is the store used by the controllers
+var DB Store
+
+// Open sets DB from the config. Redis is used unless STORE=memory, and a
+// missing REDIS_ADDR is fatal so a deploy can't silently lose its data.
+func Open() {
+	if config.Conf.Store == "memory" {
+		DB = NewMemoryStore()
+		log.Println("Using in-memory store...")
+		return
+	}
+
+	if config.Conf.RedisAddress == "" {
+		log.Fatal("REDIS_ADDR isn't set, set STORE=memory to run without redis")
 	}
 
 	client := redis.NewClient(&redis.Options{
@@ -27,6 +36,6 @@ func init() {
 		log.Fatal(err)
 	}
 	
-	RedisClient = client
+	DB = NewRedisStore(client)
 	log.Println("Connected to redis...")
-}
\ No newline at end of file
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Leaderboards are sorted sets of player ids at
+// Leaderboard:<board>:<window>[:<period>], highest score first. Monthly and
+// weekly boards start empty every period and expire a while after it ends.
+const (
+	LeaderboardPrefix = "Leaderboard:"
+
+	SpeedBoard   = "speed"
+	PointsBoard  = "points"
+	WinRateBoard = "winRate"
+	RatingBoard  = "rating"
+
+	// Games played and won in the window, used for the win rate
+	playedBoard = "played"
+	wonBoard    = "won"
+
+	AllTime = "all"
+	Monthly = "month"
+	Weekly  = "week"
+
+	// Players need this many games in the window to be ranked by win rate
+	MinWinRateGames = 5
+
+	MonthlyExpiry = 62 * 24 * time.Hour
+	WeeklyExpiry  = 14 * 24 * time.Hour
+)
+
+var Leaderboards = []string{SpeedBoard, PointsBoard, WinRateBoard, RatingBoard}
+var LeaderboardWindows = []string{AllTime, Monthly, Weekly}
+
+type LeaderboardEntry struct {
+	Rank     int     `json:"rank"`
+	Name     string  `json:"name"`
+	Score    float64 `json:"score"`
+	PlayerId string  `json:"-"`
+}
+
+type Leaderboard struct {
+	Entries []LeaderboardEntry `json:"entries"`
+	Total   int                `json:"total"`
+}
+
+// leaderboardKey returns the key of a board for the period now is in
+func leaderboardKey(board string, window string, now time.Time) string {
+	now = now.UTC()
+
+	switch window {
+	case Monthly:
+		return fmt.Sprintf("%s%s:%s:%s", LeaderboardPrefix, board, window, now.Format("2006-01"))
+	case Weekly:
+		year, week := now.ISOWeek()
+		return fmt.Sprintf("%s%s:%s:%d-W%02d", LeaderboardPrefix, board, window, year, week)
+	}
+	return LeaderboardPrefix + board + ":" + AllTime
+}
+
+// leaderboardKeys returns the keys of a board for every window
+func leaderboardKeys(board string, now time.Time) []string {
+	var keys []string
+	for _, window := range LeaderboardWindows {
+		keys = append(keys, leaderboardKey(board, window, now))
+	}
+	return keys
+}
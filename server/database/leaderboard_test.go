@@ -0,0 +1,115 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestLeaderboardKey(t *testing.T) {
+	tests := []struct {
+		window string
+		now    time.Time
+		key    string
+	}{
+		{AllTime, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC), "Leaderboard:speed:all"},
+		{Monthly, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC), "Leaderboard:speed:month:2026-03"},
+		{Monthly, time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC), "Leaderboard:speed:month:2026-03"},
+		{Monthly, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), "Leaderboard:speed:month:2026-04"},
+		// Periods go by UTC wherever the server is
+		{Monthly, time.Date(2026, 4, 1, 1, 0, 0, 0, time.FixedZone("CET", 2 * 60 * 60)), "Leaderboard:speed:month:2026-03"},
+		{Weekly, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC), "Leaderboard:speed:week:2026-W11"},
+		{Weekly, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), "Leaderboard:speed:week:2026-W12"},
+		// Weeks belong to the year of their Thursday
+		{Weekly, time.Date(2027, 1, 1, 12, 0, 0, 0, time.UTC), "Leaderboard:speed:week:2026-W53"},
+		{Weekly, time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC), "Leaderboard:speed:week:2025-W01"},
+	}
+
+	for _, test := range tests {
+		if key := leaderboardKey(SpeedBoard, test.window, test.now); key != test.key {
+			t.Errorf("%s at %v is %s, want %s", test.window, test.now, key, test.key)
+		}
+	}
+}
+
+func testLeaderboardWindows(t *testing.T, store Store) []string {
+	fast, err := store.CreatePlayer("test-" + uuid.NewV4().String(), "fast", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	steady, err := store.CreatePlayer("test-" + uuid.NewV4().String(), "steady", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One fast game, and enough steady ones to have a win rate
+	store.PlayerPlayedGame(fast, GameResult{Speed: 120, Points: 4, Won: true})
+	for i := 0; i < MinWinRateGames; i++ {
+		store.PlayerPlayedGame(steady, GameResult{Speed: 60 + float64(i), Points: 3, Won: i % 2 == 0})
+	}
+
+	for _, window := range LeaderboardWindows {
+		scores := map[string]map[string]float64{
+			SpeedBoard: {fast: 120, steady: 64},
+			PointsBoard: {fast: 4, steady: 15},
+			WinRateBoard: {steady: 0.6},
+		}
+
+		for board, want := range scores {
+			for _, player_id := range []string{fast, steady} {
+				entry, ok := store.GetLeaderboardRank(board, window, player_id)
+				score, ranked := want[player_id]
+				if ok != ranked || entry.Score != score {
+					t.Errorf("%s %s board has %s at %+v, want %v", window, board, player_id, entry, score)
+				}
+			}
+		}
+
+		first, _ := store.GetLeaderboardRank(SpeedBoard, window, fast)
+		second, _ := store.GetLeaderboardRank(SpeedBoard, window, steady)
+		if first.Rank >= second.Rank || first.Name != "fast" {
+			t.Errorf("%s speed board ranks %+v and %+v", window, first, second)
+		}
+	}
+
+	return []string{fast, steady}
+}
+
+func TestMemoryLeaderboardWindows(t *testing.T) {
+	store := NewMemoryStore()
+	testLeaderboardWindows(t, store)
+
+	board, err := store.GetLeaderboard(PointsBoard, Weekly, 0, 10)
+	if err != nil || board.Total != 2 || len(board.Entries) != 2 || board.Entries[0].Name != "steady" {
+		t.Errorf("weekly points board is %+v: %v", board, err)
+	}
+	if board, _ := store.GetLeaderboard(PointsBoard, Weekly, 2, 10); len(board.Entries) != 0 || board.Total != 2 {
+		t.Errorf("past the end of the board is %+v", board)
+	}
+}
+
+// Monthly and weekly boards expire a while after their period, the all time
+// ones never do
+func TestRedisLeaderboardExpiry(t *testing.T) {
+	store := redisTestStore(t)
+	players := testLeaderboardWindows(t, store)
+	defer func() {
+		for _, player_id := range players {
+			removePlayer(store, player_id)
+		}
+	}()
+
+	expiry := map[string]time.Duration{AllTime: -1, Monthly: MonthlyExpiry, Weekly: WeeklyExpiry}
+	for _, board := range []string{SpeedBoard, PointsBoard, playedBoard, wonBoard, WinRateBoard} {
+		for window, want := range expiry {
+			ttl, err := store.client.TTL(Ctx, leaderboardKey(board, window, time.Now())).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (want < 0 && ttl >= 0) || (want > 0 && (ttl > want || ttl < want - time.Minute)) {
+				t.Errorf("%s %s board expires in %v, want %v", window, board, ttl, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Every finished game, or round of a match, is kept as a match document at
+// Match:<id>. Registered players have the ids of their matches in a sorted
+// set at Player:<sub>:Matches scored by when the game ended.
+const (
+	MatchPrefix   = "Match:"
+	MatchesSuffix = ":Matches"
+	MatchExpiry   = 90 * 24 * time.Hour
+)
+
+type WordError struct {
+	Word   int    `json:"word"`
+	Text   string `json:"text"`
+	Errors int    `json:"errors"`
+}
+
+type Match struct {
+	Id           string             `json:"id"`
+	GameId       string             `json:"gameId"`
+	// Rematches of the game before this one, to find its replay
+	Rematch      int                `json:"rematch"`
+	Type         string             `json:"type"`
+	Ranked       bool               `json:"ranked"`
+	Round        int                `json:"round"`
+	Rounds       int                `json:"rounds"`
+	TweetId      string             `json:"tweetId"`
+	Tweet        string             `json:"tweet"`
+	Author       string             `json:"author"`
+	AuthorHandle string             `json:"authorHandle"`
+	// The game's settings as they were sent to the players
+	Settings     json.RawMessage    `json:"settings"`
+	StartTime    time.Time          `json:"startTime"`
+	EndTime      time.Time          `json:"endTime"`
+	Participants []MatchParticipant `json:"participants"`
+}
+
+type MatchParticipant struct {
+	PlayerId      string  `json:"-"`
+	Name          string  `json:"name"`
+	Speed         float64 `json:"speed"`
+	RawSpeed      float64 `json:"rawSpeed"`
+	Accuracy      float64 `json:"accuracy"`
+	Consistency   float64 `json:"consistency"`
+	Progress      int     `json:"progress"`
+	Finished      bool    `json:"finished"`
+	Guess         string  `json:"guess"`
+	GuessedAuthor bool    `json:"guessedAuthor"`
+	Placement     int     `json:"placement"`
+	Points        float64 `json:"points"`
+	RatingChange  float64 `json:"ratingChange,omitempty"`
+	// Voided results keep no speeds and score nothing
+	Voided        bool    `json:"voided,omitempty"`
+	// Mistakes in each word the player got wrong
+	WordErrors    []WordError `json:"wordErrors,omitempty"`
+	// Set when the player asking for the match took part in it
+	IsUser        bool    `json:"isUser"`
+}
+
+// storedMatch is a match as it is kept. Player ids aren't sent to clients,
+// so they are kept next to the participants instead.
+type storedMatch struct {
+	Match
+	PlayerIds []string `json:"playerIds"`
+}
+
+func storeMatch(match Match) storedMatch {
+	stored := storedMatch{Match: match}
+	for i := range match.Participants {
+		stored.PlayerIds = append(stored.PlayerIds, match.Participants[i].PlayerId)
+	}
+	return stored
+}
+
+func (m storedMatch) match() Match {
+	match := m.Match.copy()
+	for i := range match.Participants {
+		if i < len(m.PlayerIds) {
+			match.Participants[i].PlayerId = m.PlayerIds[i]
+		}
+	}
+	return match
+}
+
+// copy returns the match with its own participants, so marking them for
+// one request doesn't change the stored match
+func (m Match) copy() Match {
+	m.Participants = append([]MatchParticipant(nil), m.Participants...)
+	return m
+}
@@ -0,0 +1,585 @@
+package database
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+var ErrNotFound = errors.New("not found")
+var ErrLinked = errors.New("already linked")
+
+// MemoryStore keeps everything in process memory, so nothing survives a
+// restart. It mirrors the behaviour of RedisStore.
+type MemoryStore struct {
+	mu      sync.Mutex
+	players map[string]*PlayerRedis
+	subjects map[string]string
+	owners  map[string]string
+	games   map[string]*GameRedis
+	ratings map[string][]RatingChange
+	boards  map[string]map[string]float64
+	matches map[string]Match
+	history map[string][]string
+	replays map[string]map[int]Replay
+	runs    map[string]map[string]Run
+	reviews []FlaggedResult
+	stats   Stats
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		players: make(map[string]*PlayerRedis),
+		subjects: make(map[string]string),
+		owners:  make(map[string]string),
+		games:   make(map[string]*GameRedis),
+		ratings: make(map[string][]RatingChange),
+		boards:  make(map[string]map[string]float64),
+		matches: make(map[string]Match),
+		history: make(map[string][]string),
+		replays: make(map[string]map[int]Replay),
+		runs:    make(map[string]map[string]Run),
+	}
+}
+
+func (s *MemoryStore) CreateGame(
+	state string, 
+	tweet_id string, 
+	creator string, 
+	max_players int, 
+	time_limit int,
+) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id_str := GamePrefix + uuid.NewV4().String()
+	s.games[id_str] = &GameRedis{
+		Id: id_str,
+		State: state,
+		Players: make(map[string]bool),
+	}
+	return id_str, nil
+}
+
+func (s *MemoryStore) DeleteGame(game_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.games, game_id)
+	return nil
+}
+
+func (s *MemoryStore) UpdateGameStatus(game_id string, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	game, ok := s.games[game_id]
+	if !ok {
+		return ErrNotFound
+	}
+	game.State = state
+	return nil
+}
+
+func (s *MemoryStore) AddPlayerToGame(game_id string, player_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	game, ok := s.games[game_id]
+	if !ok {
+		return ErrNotFound
+	}
+	game.Players[player_id] = true
+	return nil
+}
+
+func (s *MemoryStore) RemovePlayerFromGame(game_id string, player_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	game, ok := s.games[game_id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(game.Players, player_id)
+	return nil
+}
+
+func (s *MemoryStore) CreatePlayer(subject string, name string, email string, picture string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := PlayerPrefix + subject
+	if _, ok := s.subjects[subject]; ok {
+		return "", ErrLinked
+	}
+	if _, ok := s.owners[id]; ok {
+		return "", ErrLinked
+	}
+	if _, ok := s.players[id]; ok {
+		return "", ErrLinked
+	}
+
+	s.subjects[subject] = id
+	s.owners[id] = subject
+	s.players[id] = &PlayerRedis{
+		Id: id,
+		Name: name,
+		Email: email,
+		Picture: picture,
+		SelectedKeyboardId: 0,
+		KeyboardsOwned: map[int]bool{0: true},
+	}
+	return id, nil
+}
+
+func (s *MemoryStore) PlayerExists(player_id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.players[player_id]
+	return ok
+}
+
+func (s *MemoryStore) GetSubjectPlayer(subject string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player_id, ok := s.subjects[subject]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return player_id, nil
+}
+
+func (s *MemoryStore) LinkSubject(subject string, player_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subjects[subject]; ok {
+		return ErrLinked
+	}
+	if _, ok := s.owners[player_id]; ok {
+		return ErrLinked
+	}
+	s.subjects[subject] = player_id
+	s.owners[player_id] = subject
+	return nil
+}
+
+func (s *MemoryStore) PlayerPlayedGame(player_id string, result GameResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	played := float64(player.MatchesPlayed + 1)
+	player.AvgAccruacy = player.AvgAccruacy + ((result.Accuracy - player.AvgAccruacy) / played)
+	player.AvgSpeed = player.AvgSpeed + ((result.Speed - player.AvgSpeed) / played)
+	player.AvgRawSpeed = player.AvgRawSpeed + ((result.RawSpeed - player.AvgRawSpeed) / played)
+	player.AvgConsistency = player.AvgConsistency + ((result.Consistency - player.AvgConsistency) / played)
+
+	player.Points += result.Points
+	if result.Speed > player.BestSpeed { player.BestSpeed = result.Speed }
+
+	player.MatchesPlayed += 1
+	if result.Won { player.MatchesWon += 1 }
+
+	now := time.Now()
+	for _, window := range LeaderboardWindows {
+		speed := s.board(SpeedBoard, window, now)
+		if best, ok := speed[player_id]; !ok || result.Speed > best {
+			speed[player_id] = result.Speed
+		}
+		s.board(PointsBoard, window, now)[player_id] += result.Points
+
+		played := s.board(playedBoard, window, now)
+		won := s.board(wonBoard, window, now)
+		played[player_id] += 1
+		if result.Won { won[player_id] += 1 }
+		if played[player_id] >= MinWinRateGames {
+			s.board(WinRateBoard, window, now)[player_id] = won[player_id] / played[player_id]
+		}
+	}
+
+	return nil
+}
+
+// board returns the scores of a leaderboard, creating it if needed
+func (s *MemoryStore) board(board string, window string, now time.Time) map[string]float64 {
+	key := leaderboardKey(board, window, now)
+	if _, ok := s.boards[key]; !ok {
+		s.boards[key] = make(map[string]float64)
+	}
+	return s.boards[key]
+}
+
+// ranked returns a leaderboard sorted like a redis sorted set read from
+// the top
+func (s *MemoryStore) ranked(board string, window string) []LeaderboardEntry {
+	var entries []LeaderboardEntry
+	for player_id, score := range s.boards[leaderboardKey(board, window, time.Now())] {
+		entries = append(entries, LeaderboardEntry{PlayerId: player_id, Score: score})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].PlayerId > entries[j].PlayerId
+	})
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if player, ok := s.players[entries[i].PlayerId]; ok {
+			entries[i].Name = player.Name
+		}
+	}
+	return entries
+}
+
+func (s *MemoryStore) GetLeaderboard(board string, window string, offset int, count int) (Leaderboard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.ranked(board, window)
+	leaderboard := Leaderboard{Entries: []LeaderboardEntry{}, Total: len(entries)}
+
+	if offset < len(entries) {
+		end := offset + count
+		if end > len(entries) { end = len(entries) }
+		leaderboard.Entries = append(leaderboard.Entries, entries[offset:end]...)
+	}
+	return leaderboard, nil
+}
+
+func (s *MemoryStore) GetLeaderboardRank(board string, window string, player_id string) (LeaderboardEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.ranked(board, window) {
+		if entry.PlayerId == player_id {
+			return entry, true
+		}
+	}
+	return LeaderboardEntry{}, false
+}
+
+func (s *MemoryStore) GetPlayerRating(player_id string) (PlayerRating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return PlayerRating{}, ErrNotFound
+	}
+
+	return PlayerRating{
+		Rating: player.Rating,
+		Deviation: player.RatingDeviation,
+		Volatility: player.RatingVolatility,
+		Games: player.RatedGames,
+	}, nil
+}
+
+func (s *MemoryStore) UpdatePlayerRating(player_id string, change RatingChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	player.Rating = change.Rating
+	player.RatingDeviation = change.Deviation
+	player.RatingVolatility = change.Volatility
+	player.RatedGames += 1
+
+	history := append([]RatingChange{change}, s.ratings[player_id]...)
+	if len(history) > RatingHistoryLimit {
+		history = history[:RatingHistoryLimit]
+	}
+	s.ratings[player_id] = history
+
+	for _, window := range LeaderboardWindows {
+		s.board(RatingBoard, window, time.Now())[player_id] = change.Rating
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetRatingHistory(player_id string, count int) []RatingChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.ratings[player_id]
+	if len(history) > count {
+		history = history[:count]
+	}
+	return append([]RatingChange{}, history...)
+}
+
+func (s *MemoryStore) GetPlayerStats(player_id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]interface{})
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return result
+	}
+
+	result["Points"] = player.Points
+	result["AvgSpeed"] = player.AvgSpeed
+	result["BestSpeed"] = player.BestSpeed
+	result["MatchesWon"] = player.MatchesWon 
+	result["AvgAccuracy"] = player.AvgAccruacy
+	result["AvgRawSpeed"] = player.AvgRawSpeed
+	result["MatchesPlayed"] = player.MatchesPlayed
+	result["AvgConsistency"] = player.AvgConsistency
+
+	return result
+}
+
+func (s *MemoryStore) GetPlayerSelectedKeyboard(player_id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if player, ok := s.players[player_id]; ok {
+		return player.SelectedKeyboardId
+	}
+	return 0
+}
+
+func (s *MemoryStore) GetPlayerKeyboards(player_id string) []PlayerKeyboard {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []PlayerKeyboard{}
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return result
+	}
+
+	for i := range player.KeyboardsOwned {
+		result = append(result, PlayerKeyboard{
+			KeyboardId: i,
+			Selected: i == player.SelectedKeyboardId,
+		})
+	}
+
+	return result
+}
+
+func (s *MemoryStore) ChangePlayerName(player_id, new_name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return ErrNotFound
+	}
+	player.Name = new_name
+	return nil
+}
+
+func (s *MemoryStore) ChangePlayerKeyboard(player_id string, new_keyboard_id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return ErrNotFound
+	}
+	if _, ok := player.KeyboardsOwned[new_keyboard_id]; ok {
+		player.SelectedKeyboardId = new_keyboard_id
+	}
+	return nil
+}
+
+func (s *MemoryStore) GrantPlayerKeyboard(player_id string, keyboard_id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[player_id]
+	if !ok {
+		return ErrNotFound
+	}
+	player.KeyboardsOwned[keyboard_id] = true
+	return nil
+}
+
+func (s *MemoryStore) SaveMatch(match Match) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match.Id = uuid.NewV4().String()
+	s.matches[match.Id] = match.copy()
+
+	for i := range match.Participants {
+		player_id := match.Participants[i].PlayerId
+		if strings.HasPrefix(player_id, PlayerPrefix) {
+			s.history[player_id] = append(s.history[player_id], match.Id)
+		}
+	}
+	return match.Id, nil
+}
+
+func (s *MemoryStore) GetMatch(match_id string) (Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match, ok := s.matches[match_id]
+	if !ok {
+		return match, ErrNotFound
+	}
+	return match.copy(), nil
+}
+
+func (s *MemoryStore) GetPlayerMatches(player_id string, offset int, count int) ([]Match, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches := []Match{}
+	ids := s.history[player_id]
+
+	// Newest first
+	for i := len(ids) - 1 - offset; i >= 0 && len(matches) < count; i-- {
+		matches = append(matches, s.matches[ids[i]].copy())
+	}
+	return matches, len(ids), nil
+}
+
+func (s *MemoryStore) SaveReplay(replay Replay) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := replayKey(replay.GameId, replay.Rematch)
+	if s.replays[key] == nil {
+		s.replays[key] = make(map[int]Replay)
+	}
+	s.replays[key][replay.Round] = replay
+	return nil
+}
+
+func (s *MemoryStore) GetReplays(game_id string, rematch int) ([]Replay, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replays := []Replay{}
+	for _, replay := range s.replays[replayKey(game_id, rematch)] {
+		replays = append(replays, replay)
+	}
+	if len(replays) == 0 {
+		return replays, ErrNotFound
+	}
+
+	sort.Slice(replays, func(i, j int) bool {
+		return replays[i].Round < replays[j].Round
+	})
+	return replays, nil
+}
+
+func (s *MemoryStore) SavePersonalBest(player_id string, run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := runsKey(run.TweetId, run.TypingMode)
+	if s.runs[key] == nil {
+		s.runs[key] = make(map[string]Run)
+	}
+
+	if best, ok := s.runs[key][player_id]; !ok || run.Speed > best.Speed {
+		s.runs[key][player_id] = run
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetPersonalBest(tweet_id string, typing_mode string, player_id string) (Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[runsKey(tweet_id, typing_mode)][player_id]
+	if !ok {
+		return run, ErrNotFound
+	}
+	return run, nil
+}
+
+func (s *MemoryStore) GetTopRun(tweet_id string, typing_mode string) (Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var top Run
+	found := false
+	for _, run := range s.runs[runsKey(tweet_id, typing_mode)] {
+		if !found || run.Speed > top.Speed {
+			top = run
+			found = true
+		}
+	}
+
+	if !found {
+		return top, ErrNotFound
+	}
+	return top, nil
+}
+
+func (s *MemoryStore) FlagResult(result FlaggedResult) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result.Id = uuid.NewV4().String()
+	s.reviews = append(s.reviews, result)
+	return result.Id, nil
+}
+
+func (s *MemoryStore) GetFlaggedResults(offset int, count int) ([]FlaggedResult, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := []FlaggedResult{}
+	for i := offset; i < len(s.reviews) && len(results) < count; i++ {
+		results = append(results, s.reviews[i])
+	}
+	return results, len(s.reviews), nil
+}
+
+func (s *MemoryStore) ResolveFlaggedResult(review_id string) (FlaggedResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.reviews {
+		if s.reviews[i].Id == review_id {
+			result := s.reviews[i]
+			s.reviews = append(s.reviews[:i], s.reviews[i+1:]...)
+			return result, nil
+		}
+	}
+	return FlaggedResult{}, ErrNotFound
+}
+
+func (s *MemoryStore) IncrementGamesCreated() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.GamesCreated += 1
+	return nil
+}
+
+func (s *MemoryStore) IncrementAccountsCreated() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.AccountsCreated += 1
+	return nil
+}
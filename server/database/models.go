@@ -6,23 +6,32 @@ const (
 	TweetPrefix    = "Tweet:"
 	GuestPrefix    = "Guest:"
 	GamePrefix     = "Game:"
-	GameQueueKey   = "Queue"
+	SubjectPrefix  = "Subject:"
 	StatsKey       = "Stats"
+	KeyboardsSuffix = ":Keyboards"
+	RatingHistorySuffix = ":RatingHistory"
+	SubjectSuffix = ":Subject"
 )
 
 type PlayerRedis struct {
-	Id                 string       `json:"id"`
-	Name               string       `json:"name"`
-	Email              string       `json:"email"`
-	Picture            string       `json:"picture"`
-	AvgAccruacy        float64      `json:"avgAccuracy"`
-	AvgSpeed           float64      `json:"avgSpeed"`
-	BestSpeed          float64      `json:"bestSpeed"`
-	MatchesPlayed      int          `json:"matchesPlayed"`
-	MatchesWon         int          `json:"matchesWon"`
-	Points             float64      `json:"points"`
-	SelectedKeyboardId int          `json:"selectedKeyboardId"`
-	KeyboardsOwned     map[int]bool `json:"keyboardsOwned"`
+	Id                 string       `json:"id" redis:"id"`
+	Name               string       `json:"name" redis:"name"`
+	Email              string       `json:"email" redis:"email"`
+	Picture            string       `json:"picture" redis:"picture"`
+	AvgAccruacy        float64      `json:"avgAccuracy" redis:"avgAccuracy"`
+	AvgSpeed           float64      `json:"avgSpeed" redis:"avgSpeed"`
+	BestSpeed          float64      `json:"bestSpeed" redis:"bestSpeed"`
+	AvgRawSpeed        float64      `json:"avgRawSpeed" redis:"avgRawSpeed"`
+	AvgConsistency     float64      `json:"avgConsistency" redis:"avgConsistency"`
+	MatchesPlayed      int          `json:"matchesPlayed" redis:"matchesPlayed"`
+	MatchesWon         int          `json:"matchesWon" redis:"matchesWon"`
+	Points             float64      `json:"points" redis:"points"`
+	SelectedKeyboardId int          `json:"selectedKeyboardId" redis:"selectedKeyboardId"`
+	Rating             float64      `json:"rating" redis:"rating"`
+	RatingDeviation    float64      `json:"ratingDeviation" redis:"ratingDeviation"`
+	RatingVolatility   float64      `json:"ratingVolatility" redis:"ratingVolatility"`
+	RatedGames         int          `json:"ratedGames" redis:"ratedGames"`
+	KeyboardsOwned     map[int]bool `json:"keyboardsOwned" redis:"-"`
 }
 
 type GameRedis struct {
@@ -34,4 +43,4 @@ type GameRedis struct {
 type Stats struct {
 	GamesCreated    uint64 `json:"gamesCreated"`
 	AccountsCreated uint64 `json:"accountsCreated"`
-}
\ No newline at end of file
+}
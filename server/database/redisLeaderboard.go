@@ -0,0 +1,71 @@
+package database
+
+import (
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func (s *RedisStore) GetLeaderboard(board string, window string, offset int, count int) (Leaderboard, error) {
+	leaderboard := Leaderboard{Entries: []LeaderboardEntry{}}
+	key := leaderboardKey(board, window, time.Now())
+
+	total, err := s.client.ZCard(Ctx, key).Result()
+	if err != nil {
+		return leaderboard, err
+	}
+	leaderboard.Total = int(total)
+
+	scores, err := s.client.ZRevRangeWithScores(Ctx, key, int64(offset), int64(offset + count - 1)).Result()
+	if err != nil {
+		return leaderboard, err
+	}
+
+	for i := range scores {
+		player_id, _ := scores[i].Member.(string)
+		leaderboard.Entries = append(leaderboard.Entries, LeaderboardEntry{
+			Rank: offset + i + 1,
+			Score: scores[i].Score,
+			PlayerId: player_id,
+		})
+	}
+
+	s.addNames(leaderboard.Entries)
+	return leaderboard, nil
+}
+
+func (s *RedisStore) GetLeaderboardRank(board string, window string, player_id string) (LeaderboardEntry, bool) {
+	key := leaderboardKey(board, window, time.Now())
+
+	rank, err := s.client.ZRevRank(Ctx, key, player_id).Result()
+	if err != nil {
+		return LeaderboardEntry{}, false
+	}
+
+	score, err := s.client.ZScore(Ctx, key, player_id).Result()
+	if err != nil {
+		return LeaderboardEntry{}, false
+	}
+
+	entries := []LeaderboardEntry{{Rank: int(rank) + 1, Score: score, PlayerId: player_id}}
+	s.addNames(entries)
+	return entries[0], true
+}
+
+// addNames looks up the names of the players on a leaderboard
+func (s *RedisStore) addNames(entries []LeaderboardEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	pipe := s.client.Pipeline()
+	names := make([]*redis.StringCmd, len(entries))
+	for i := range entries {
+		names[i] = pipe.HGet(Ctx, entries[i].PlayerId, "name")
+	}
+	pipe.Exec(Ctx)
+
+	for i := range entries {
+		entries[i].Name = names[i].Val()
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (s *RedisStore) SaveMatch(match Match) (string, error) {
+	match.Id = uuid.NewV4().String()
+
+	match_json, err := json.Marshal(storeMatch(match))
+	if err != nil {
+		return "", err
+	}
+
+	// Drop matches from the index once their documents have expired
+	ended := match.EndTime.Unix()
+	expired := strconv.FormatInt(match.EndTime.Add(-MatchExpiry).Unix(), 10)
+
+	_, err = s.client.TxPipelined(Ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(Ctx, MatchPrefix + match.Id, match_json, MatchExpiry)
+
+		for i := range match.Participants {
+			player_id := match.Participants[i].PlayerId
+			if !strings.HasPrefix(player_id, PlayerPrefix) {
+				continue
+			}
+
+			pipe.ZAdd(Ctx, player_id + MatchesSuffix, &redis.Z{Score: float64(ended), Member: match.Id})
+			pipe.ZRemRangeByScore(Ctx, player_id + MatchesSuffix, "-inf", expired)
+		}
+		return nil
+	})
+
+	return match.Id, err
+}
+
+func (s *RedisStore) GetMatch(match_id string) (Match, error) {
+	var match storedMatch
+
+	data, err := s.client.Get(Ctx, MatchPrefix + match_id).Result()
+	if err == redis.Nil {
+		return match.Match, ErrNotFound
+	} else if err != nil {
+		return match.Match, err
+	}
+
+	err = json.Unmarshal([]byte(data), &match)
+	return match.match(), err
+}
+
+// GetPlayerMatches returns a page of a player's matches, newest first, and
+// how many they have
+func (s *RedisStore) GetPlayerMatches(player_id string, offset int, count int) ([]Match, int, error) {
+	matches := []Match{}
+	index := player_id + MatchesSuffix
+
+	total, err := s.client.ZCard(Ctx, index).Result()
+	if err != nil {
+		return matches, 0, err
+	}
+
+	ids, err := s.client.ZRevRange(Ctx, index, int64(offset), int64(offset + count - 1)).Result()
+	if err != nil || len(ids) == 0 {
+		return matches, int(total), err
+	}
+
+	keys := make([]string, len(ids))
+	for i := range ids {
+		keys[i] = MatchPrefix + ids[i]
+	}
+
+	documents, err := s.client.MGet(Ctx, keys...).Result()
+	if err != nil {
+		return matches, int(total), err
+	}
+
+	for i := range documents {
+		data, ok := documents[i].(string)
+		if !ok {
+			continue
+		}
+
+		var match storedMatch
+		if err := json.Unmarshal([]byte(data), &match); err == nil {
+			matches = append(matches, match.match())
+		}
+	}
+
+	return matches, int(total), nil
+}
@@ -0,0 +1,462 @@
+package database
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Players are stored as a hash at Player:<sub>, keyed by the subject of
+// their ID token, with the keyboards they own in a set at
+// Player:<sub>:Keyboards. Players from before ID tokens were checked are
+// still keyed by email. Every update is a single command or
+// script so concurrent games can't overwrite each other's results. Scripts
+// that update the leaderboards get their keys after the player's own.
+
+var playedGameScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+
+local played = tonumber(redis.call('HGET', KEYS[1], 'matchesPlayed') or '0')
+local best_speed = tonumber(redis.call('HGET', KEYS[1], 'bestSpeed') or '0')
+local speed = tonumber(ARGV[1])
+
+local averages = {'avgSpeed', 'avgRawSpeed', 'avgAccuracy', 'avgConsistency'}
+for i, field in ipairs(averages) do
+	local avg = tonumber(redis.call('HGET', KEYS[1], field) or '0')
+	local value = tonumber(ARGV[i])
+	avg = avg + ((value - avg) / (played + 1))
+	redis.call('HSET', KEYS[1], field, tostring(avg))
+end
+
+if speed > best_speed then
+	redis.call('HSET', KEYS[1], 'bestSpeed', ARGV[1])
+end
+
+redis.call('HINCRBY', KEYS[1], 'matchesPlayed', 1)
+if ARGV[5] == '1' then
+	redis.call('HINCRBY', KEYS[1], 'matchesWon', 1)
+end
+redis.call('HINCRBYFLOAT', KEYS[1], 'points', ARGV[6])
+
+-- Leaderboards for each window: all time, monthly and weekly
+local expiry = {0, tonumber(ARGV[8]), tonumber(ARGV[9])}
+for w = 1, 3 do
+	local speed_key, points_key = KEYS[3 + w], KEYS[6 + w]
+	local played_key, won_key, win_rate_key = KEYS[9 + w], KEYS[12 + w], KEYS[15 + w]
+
+	local window_best = tonumber(redis.call('ZSCORE', speed_key, KEYS[1]) or '-1')
+	if speed > window_best then
+		redis.call('ZADD', speed_key, ARGV[1], KEYS[1])
+	end
+	redis.call('ZINCRBY', points_key, ARGV[6], KEYS[1])
+
+	local window_played = tonumber(redis.call('ZINCRBY', played_key, 1, KEYS[1]))
+	local window_won = tonumber(redis.call('ZSCORE', won_key, KEYS[1]) or '0')
+	if ARGV[5] == '1' then
+		window_won = tonumber(redis.call('ZINCRBY', won_key, 1, KEYS[1]))
+	end
+	if window_played >= tonumber(ARGV[7]) then
+		redis.call('ZADD', win_rate_key, window_won / window_played, KEYS[1])
+	end
+
+	if expiry[w] > 0 then
+		for _, key in ipairs({speed_key, points_key, played_key, won_key, win_rate_key}) do
+			redis.call('EXPIRE', key, expiry[w])
+		end
+	end
+end
+return 1
+`)
+
+var ratingScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], 'rating', ARGV[1], 'ratingDeviation', ARGV[2], 'ratingVolatility', ARGV[3])
+redis.call('HINCRBY', KEYS[1], 'ratedGames', 1)
+redis.call('LPUSH', KEYS[3], ARGV[4])
+redis.call('LTRIM', KEYS[3], 0, tonumber(ARGV[5]) - 1)
+
+local expiry = {0, tonumber(ARGV[6]), tonumber(ARGV[7])}
+for w = 1, 3 do
+	redis.call('ZADD', KEYS[3 + w], ARGV[1], KEYS[1])
+	if expiry[w] > 0 then
+		redis.call('EXPIRE', KEYS[3 + w], expiry[w])
+	end
+end
+return 1
+`)
+
+var setFieldScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
+
+var selectKeyboardScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if redis.call('SISMEMBER', KEYS[2], ARGV[1]) == 1 then
+	redis.call('HSET', KEYS[1], 'selectedKeyboardId', ARGV[1])
+end
+return 1
+`)
+
+var grantKeyboardScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('SADD', KEYS[2], ARGV[1])
+return 1
+`)
+
+// Creates a player and links its subject to it, unless either is taken
+var createPlayerScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 or redis.call('EXISTS', KEYS[2]) == 1 or redis.call('EXISTS', KEYS[3]) == 1 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+redis.call('SET', KEYS[2], ARGV[1])
+redis.call('HSET', KEYS[3], unpack(ARGV, 3))
+redis.call('SADD', KEYS[4], 0)
+return 1
+`)
+
+var linkSubjectScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 or redis.call('EXISTS', KEYS[2]) == 1 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+redis.call('SET', KEYS[2], ARGV[1])
+return 1
+`)
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func playerFields(player PlayerRedis) map[string]interface{} {
+	return map[string]interface{}{
+		"id": player.Id,
+		"name": player.Name,
+		"email": player.Email,
+		"picture": player.Picture,
+		"avgAccuracy": formatFloat(player.AvgAccruacy),
+		"avgSpeed": formatFloat(player.AvgSpeed),
+		"bestSpeed": formatFloat(player.BestSpeed),
+		"avgRawSpeed": formatFloat(player.AvgRawSpeed),
+		"avgConsistency": formatFloat(player.AvgConsistency),
+		"matchesPlayed": player.MatchesPlayed,
+		"matchesWon": player.MatchesWon,
+		"points": formatFloat(player.Points),
+		"selectedKeyboardId": player.SelectedKeyboardId,
+		"rating": formatFloat(player.Rating),
+		"ratingDeviation": formatFloat(player.RatingDeviation),
+		"ratingVolatility": formatFloat(player.RatingVolatility),
+		"ratedGames": player.RatedGames,
+	}
+}
+
+// runScript runs a player script and maps a missing player to ErrNotFound
+func (s *RedisStore) runScript(script *redis.Script, player_id string, args ...interface{}) error {
+	return s.runScriptWithKeys(script, player_id, nil, args...)
+}
+
+// runScriptWithKeys runs a player script that also uses the extra keys
+func (s *RedisStore) runScriptWithKeys(
+	script *redis.Script, 
+	player_id string, 
+	extra_keys []string, 
+	args ...interface{},
+) error {
+	return s.withPlayer(player_id, func() error {
+		keys := []string{player_id, player_id + KeyboardsSuffix, player_id + RatingHistorySuffix}
+		keys = append(keys, extra_keys...)
+		found, err := script.Run(Ctx, s.client, keys, args...).Int()
+		if err != nil {
+			return err
+		}
+		if found == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// withPlayer runs fn and retries it once if the player is still stored in
+// the old JSON format
+func (s *RedisStore) withPlayer(player_id string, fn func() error) error {
+	err := fn()
+	if err != nil && strings.Contains(err.Error(), "WRONGTYPE") {
+		if err = s.migratePlayer(player_id); err != nil {
+			return err
+		}
+		err = fn()
+	}
+	return err
+}
+
+// migratePlayer converts a player stored as a JSON string into a hash
+func (s *RedisStore) migratePlayer(player_id string) error {
+	err := s.client.Watch(Ctx, func(tx *redis.Tx) error {
+		if key_type, err := tx.Type(Ctx, player_id).Result(); err != nil || key_type != "string" {
+			return err
+		}
+
+		data, err := tx.Get(Ctx, player_id).Result()
+		if err != nil {
+			return err
+		}
+
+		var player PlayerRedis
+		if err = json.Unmarshal([]byte(data), &player); err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(Ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(Ctx, player_id)
+			pipe.HSet(Ctx, player_id, playerFields(player))
+			for i := range player.KeyboardsOwned {
+				pipe.SAdd(Ctx, player_id + KeyboardsSuffix, i)
+			}
+			return nil
+		})
+		return err
+	}, player_id)
+
+	// Another request migrated the player first
+	if err == redis.TxFailedErr {
+		return nil
+	}
+	return err
+}
+
+func (s *RedisStore) getPlayer(player_id string) (PlayerRedis, error) {
+	var player PlayerRedis
+
+	err := s.withPlayer(player_id, func() error {
+		cmd := s.client.HGetAll(Ctx, player_id)
+		if fields, err := cmd.Result(); err != nil {
+			return err
+		} else if len(fields) == 0 {
+			return ErrNotFound
+		}
+		return cmd.Scan(&player)
+	})
+
+	return player, err
+}
+
+// CreatePlayer creates a player for a verified identity and links the
+// subject to it in one step. Email, name and picture are only profile data.
+// It returns ErrLinked if the subject is already linked or the player
+// already exists.
+func (s *RedisStore) CreatePlayer(subject string, name string, email string, picture string) (string, error) {
+	var player PlayerRedis
+	id := PlayerPrefix + subject
+
+	player.Id = id
+	player.Name = name
+	player.Email = email
+	player.Picture = picture
+	player.SelectedKeyboardId = 0
+
+	args := []interface{}{subject, id}
+	for field, value := range playerFields(player) {
+		args = append(args, field, value)
+	}
+
+	keys := []string{SubjectPrefix + subject, id + SubjectSuffix, id, id + KeyboardsSuffix}
+	created, err := createPlayerScript.Run(Ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return "", err
+	} else if created == 0 {
+		return "", ErrLinked
+	}
+	return id, nil
+}
+
+func (s *RedisStore) PlayerExists(player_id string) bool {
+	return s.client.Exists(Ctx, player_id).Val() == 1
+}
+
+func (s *RedisStore) GetSubjectPlayer(subject string) (string, error) {
+	player_id, err := s.client.Get(Ctx, SubjectPrefix + subject).Result()
+	if err == redis.Nil {
+		return "", ErrNotFound
+	}
+	return player_id, err
+}
+
+// LinkSubject signs the subject in as the player from now on. Each subject
+// and each player is only linked once, otherwise it returns ErrLinked.
+func (s *RedisStore) LinkSubject(subject string, player_id string) error {
+	keys := []string{SubjectPrefix + subject, player_id + SubjectSuffix}
+	linked, err := linkSubjectScript.Run(Ctx, s.client, keys, subject, player_id).Int()
+	if err != nil {
+		return err
+	} else if linked == 0 {
+		return ErrLinked
+	}
+	return nil
+}
+
+func (s *RedisStore) PlayerPlayedGame(player_id string, result GameResult) error {
+	won_str := "0"
+	if result.Won { won_str = "1" }
+
+	now := time.Now()
+	var boards []string
+	for _, board := range []string{SpeedBoard, PointsBoard, playedBoard, wonBoard, WinRateBoard} {
+		boards = append(boards, leaderboardKeys(board, now)...)
+	}
+
+	return s.runScriptWithKeys(
+		playedGameScript,
+		player_id,
+		boards,
+		formatFloat(result.Speed),
+		formatFloat(result.RawSpeed),
+		formatFloat(result.Accuracy),
+		formatFloat(result.Consistency),
+		won_str,
+		formatFloat(result.Points),
+		MinWinRateGames,
+		int(MonthlyExpiry.Seconds()),
+		int(WeeklyExpiry.Seconds()),
+	)
+}
+
+func (s *RedisStore) GetPlayerStats(player_id string) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	player, err := s.getPlayer(player_id)
+	if err != nil {
+		return result
+	}
+
+	result["Points"] = player.Points
+	result["AvgSpeed"] = player.AvgSpeed
+	result["BestSpeed"] = player.BestSpeed
+	result["MatchesWon"] = player.MatchesWon
+	result["AvgAccuracy"] = player.AvgAccruacy
+	result["AvgRawSpeed"] = player.AvgRawSpeed
+	result["MatchesPlayed"] = player.MatchesPlayed
+	result["AvgConsistency"] = player.AvgConsistency
+
+	return result
+}
+
+func (s *RedisStore) GetPlayerRating(player_id string) (PlayerRating, error) {
+	player, err := s.getPlayer(player_id)
+	if err != nil {
+		return PlayerRating{}, err
+	}
+
+	return PlayerRating{
+		Rating: player.Rating,
+		Deviation: player.RatingDeviation,
+		Volatility: player.RatingVolatility,
+		Games: player.RatedGames,
+	}, nil
+}
+
+func (s *RedisStore) UpdatePlayerRating(player_id string, change RatingChange) error {
+	change_json, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	return s.runScriptWithKeys(
+		ratingScript,
+		player_id,
+		leaderboardKeys(RatingBoard, time.Now()),
+		formatFloat(change.Rating),
+		formatFloat(change.Deviation),
+		formatFloat(change.Volatility),
+		change_json,
+		RatingHistoryLimit,
+		int(MonthlyExpiry.Seconds()),
+		int(WeeklyExpiry.Seconds()),
+	)
+}
+
+// GetRatingHistory returns a player's latest rating changes, newest first
+func (s *RedisStore) GetRatingHistory(player_id string, count int) []RatingChange {
+	history := []RatingChange{}
+
+	entries, err := s.client.LRange(Ctx, player_id + RatingHistorySuffix, 0, int64(count - 1)).Result()
+	if err != nil {
+		return history
+	}
+
+	for i := range entries {
+		var change RatingChange
+		if err := json.Unmarshal([]byte(entries[i]), &change); err == nil {
+			history = append(history, change)
+		}
+	}
+
+	return history
+}
+
+func (s *RedisStore) GetPlayerSelectedKeyboard(player_id string) int {
+	player, err := s.getPlayer(player_id)
+	if err != nil {
+		return 0
+	}
+
+	return player.SelectedKeyboardId
+}
+
+func (s *RedisStore) GetPlayerKeyboards(player_id string) []PlayerKeyboard {
+	result := []PlayerKeyboard{}
+
+	player, err := s.getPlayer(player_id)
+	if err != nil {
+		return result
+	}
+
+	owned, err := s.client.SMembers(Ctx, player_id + KeyboardsSuffix).Result()
+	if err != nil {
+		return result
+	}
+
+	for i := range owned {
+		keyboard_id, err := strconv.Atoi(owned[i])
+		if err != nil {
+			continue
+		}
+
+		result = append(
+			result,
+			PlayerKeyboard{
+				KeyboardId: keyboard_id,
+				Selected: keyboard_id == player.SelectedKeyboardId,
+			 },
+		)
+	}
+
+	return result
+}
+
+func (s *RedisStore) ChangePlayerName(player_id, new_name string) error {
+	return s.runScript(setFieldScript, player_id, "name", new_name)
+}
+
+func (s *RedisStore) ChangePlayerKeyboard(player_id string, new_keyboard_id int) error {
+	return s.runScript(selectKeyboardScript, player_id, new_keyboard_id)
+}
+
+func (s *RedisStore) GrantPlayerKeyboard(player_id string, keyboard_id int) error {
+	return s.runScript(grantKeyboardScript, player_id, keyboard_id)
+}
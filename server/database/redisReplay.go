@@ -0,0 +1,46 @@
+package database
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+func (s *RedisStore) SaveReplay(replay Replay) error {
+	replay_json, err := json.Marshal(replay)
+	if err != nil {
+		return err
+	}
+
+	key := replayKey(replay.GameId, replay.Rematch)
+	pipe := s.client.TxPipeline()
+	pipe.HSet(Ctx, key, strconv.Itoa(replay.Round), replay_json)
+	pipe.Expire(Ctx, key, ReplayExpiry)
+	_, err = pipe.Exec(Ctx)
+	return err
+}
+
+// GetReplays returns the replays of every round of a game's match, in order
+func (s *RedisStore) GetReplays(game_id string, rematch int) ([]Replay, error) {
+	replays := []Replay{}
+
+	rounds, err := s.client.HGetAll(Ctx, replayKey(game_id, rematch)).Result()
+	if err != nil {
+		return replays, err
+	}
+	if len(rounds) == 0 {
+		return replays, ErrNotFound
+	}
+
+	for _, data := range rounds {
+		var replay Replay
+		if err := json.Unmarshal([]byte(data), &replay); err == nil {
+			replays = append(replays, replay)
+		}
+	}
+
+	sort.Slice(replays, func(i, j int) bool {
+		return replays[i].Round < replays[j].Round
+	})
+	return replays, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Takes a result off the queue, so it can only be resolved once
+var resolveReviewScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return false
+end
+local result = redis.call('GET', KEYS[2])
+redis.call('DEL', KEYS[2])
+return result
+`)
+
+func (s *RedisStore) FlagResult(result FlaggedResult) (string, error) {
+	result.Id = uuid.NewV4().String()
+
+	result_json, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.client.TxPipelined(Ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(Ctx, ReviewPrefix + result.Id, result_json, 0)
+		pipe.ZAdd(Ctx, ReviewQueueKey, &redis.Z{Score: float64(result.Time.Unix()), Member: result.Id})
+		return nil
+	})
+
+	return result.Id, err
+}
+
+// GetFlaggedResults returns a page of the review queue, oldest first, and
+// how many results are waiting
+func (s *RedisStore) GetFlaggedResults(offset int, count int) ([]FlaggedResult, int, error) {
+	results := []FlaggedResult{}
+
+	total, err := s.client.ZCard(Ctx, ReviewQueueKey).Result()
+	if err != nil {
+		return results, 0, err
+	}
+
+	ids, err := s.client.ZRange(Ctx, ReviewQueueKey, int64(offset), int64(offset + count - 1)).Result()
+	if err != nil || len(ids) == 0 {
+		return results, int(total), err
+	}
+
+	keys := make([]string, len(ids))
+	for i := range ids {
+		keys[i] = ReviewPrefix + ids[i]
+	}
+
+	documents, err := s.client.MGet(Ctx, keys...).Result()
+	if err != nil {
+		return results, int(total), err
+	}
+
+	for i := range documents {
+		data, ok := documents[i].(string)
+		if !ok {
+			continue
+		}
+
+		var result FlaggedResult
+		if err := json.Unmarshal([]byte(data), &result); err == nil {
+			results = append(results, result)
+		}
+	}
+
+	return results, int(total), nil
+}
+
+// ResolveFlaggedResult takes a result off the review queue and returns it
+func (s *RedisStore) ResolveFlaggedResult(review_id string) (FlaggedResult, error) {
+	var result FlaggedResult
+
+	data, err := resolveReviewScript.Run(Ctx, s.client, []string{ReviewQueueKey, ReviewPrefix + review_id}, review_id).Text()
+	if err == redis.Nil {
+		return result, ErrNotFound
+	} else if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal([]byte(data), &result)
+	return result, err
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Keeps the run only if it beats the player's best
+var saveRunScript = redis.NewScript(`
+local best = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if best and tonumber(best) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+return 1
+`)
+
+// SavePersonalBest keeps a player's run of a tweet if it is their fastest
+func (s *RedisStore) SavePersonalBest(player_id string, run Run) error {
+	run_json, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	key := runsKey(run.TweetId, run.TypingMode)
+	return saveRunScript.Run(Ctx, s.client, []string{key, key + BestSuffix}, player_id, run.Speed, run_json).Err()
+}
+
+func (s *RedisStore) GetPersonalBest(tweet_id string, typing_mode string, player_id string) (Run, error) {
+	var run Run
+
+	data, err := s.client.HGet(Ctx, runsKey(tweet_id, typing_mode), player_id).Result()
+	if err == redis.Nil {
+		return run, ErrNotFound
+	} else if err != nil {
+		return run, err
+	}
+
+	err = json.Unmarshal([]byte(data), &run)
+	return run, err
+}
+
+// GetTopRun returns the fastest run anyone has typed of a tweet
+func (s *RedisStore) GetTopRun(tweet_id string, typing_mode string) (Run, error) {
+	key := runsKey(tweet_id, typing_mode)
+
+	best, err := s.client.ZRevRange(Ctx, key + BestSuffix, 0, 0).Result()
+	if err != nil {
+		return Run{}, err
+	}
+	if len(best) == 0 {
+		return Run{}, ErrNotFound
+	}
+
+	return s.GetPersonalBest(tweet_id, typing_mode, best[0])
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	uuid "github.com/satori/go.uuid"
+)
+
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) CreateGame(
+	state string, 
+	tweet_id string, 
+	creator string, 
+	max_players int, 
+	time_limit int,
+) (string, error) {
+
+	var game GameRedis
+	id_str := GamePrefix + uuid.NewV4().String()
+
+	game.Id = id_str
+	game.State = state
+	game.Players = make(map[string]bool)
+
+	if game_json, err := json.Marshal(game); err != nil {
+		return "", err
+	} else {
+		return id_str, s.client.Set(Ctx, id_str, game_json, 15 * time.Minute).Err()
+	}
+}
+
+func (s *RedisStore) DeleteGame(game_id string) error {
+	return s.client.Del(Ctx, game_id).Err()
+}
+
+func (s *RedisStore) UpdateGameStatus(game_id string, state string) error {
+	data, err := s.client.Get(Ctx, game_id).Result()
+
+	if err != nil {
+		return err
+	}
+
+	var game GameRedis
+	if err = json.Unmarshal([]byte(data), &game); err != nil {
+		return err
+	}
+
+	game.State = state 
+
+	if game_json, err := json.Marshal(game); err != nil {
+		return err
+	} else {
+		err = s.client.Set(Ctx, game_id, game_json, 0).Err()
+		return err
+	}
+}
+
+func (s *RedisStore) AddPlayerToGame(game_id string, player_id string) error {
+	data, err := s.client.Get(Ctx, game_id).Result()
+
+	if err != nil {
+		return err
+	}
+
+	var game GameRedis
+	if err = json.Unmarshal([]byte(data), &game); err != nil {
+		return err
+	}
+
+	game.Players[player_id] = true
+
+	if game_json, err := json.Marshal(game); err != nil {
+		return err
+	} else {
+		err = s.client.Set(Ctx, game_id, game_json, 0).Err()
+		return err
+	}
+}
+
+func (s *RedisStore) RemovePlayerFromGame(game_id string, player_id string) error {
+	data, err := s.client.Get(Ctx, game_id).Result()
+
+	if err != nil {
+		return err
+	}
+
+	var game GameRedis
+	if err = json.Unmarshal([]byte(data), &game); err != nil {
+		return err
+	}
+
+	delete(game.Players, player_id)
+
+	if game_json, err := json.Marshal(game); err != nil {
+		return err
+	} else {
+		err = s.client.Set(Ctx, game_id, game_json, 0).Err()
+		return err
+	}
+}
+
+func (s *RedisStore) createStats() error {
+	var stats Stats
+	stats.AccountsCreated = 0
+	stats.GamesCreated = 0
+
+	if stats_json, err := json.Marshal(stats); err != nil {
+		return err
+	} else {
+		return s.client.Set(Ctx, StatsKey, stats_json, 0).Err()
+	}
+}
+
+func (s *RedisStore) IncrementGamesCreated() error {
+	data, err := s.client.Get(Ctx, StatsKey).Result()
+	if err != nil {
+		if err = s.createStats(); err != nil {
+			return err
+		}
+		data, _ = s.client.Get(Ctx, StatsKey).Result()
+	}
+
+	var stats Stats
+	if err = json.Unmarshal([]byte(data), &stats); err != nil {
+		return err
+	}
+
+	stats.GamesCreated += 1
+
+	if player_json, err := json.Marshal(stats); err != nil {
+		return err
+	} else {
+		return s.client.Set(Ctx, StatsKey, player_json, 0).Err()
+	}
+}
+
+func (s *RedisStore) IncrementAccountsCreated() error {
+	data, err := s.client.Get(Ctx, StatsKey).Result()
+	if err != nil {
+		if err = s.createStats(); err != nil {
+			return err
+		}
+		data, _ = s.client.Get(Ctx, StatsKey).Result()
+	}
+
+	var stats Stats
+	if err = json.Unmarshal([]byte(data), &stats); err != nil {
+		return err
+	}
+
+	stats.AccountsCreated += 1
+	if player_json, err := json.Marshal(stats); err != nil {
+		return err
+	} else {
+		return s.client.Set(Ctx, StatsKey, player_json, 0).Err()
+	}
+}
\ No newline at end of file
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"server/text"
+	"strconv"
+	"time"
+)
+
+// The keystrokes of every round are kept as a replay in a hash at
+// Replay:<gameId>:<rematch>, one field per round, for as long as its match
+// is kept. Rematches reuse the game id, so they count up from 0.
+const (
+	ReplayPrefix = "Replay:"
+	ReplayExpiry = MatchExpiry
+
+	// Keystrokes recorded for a player in a round, past which the rest
+	// are dropped
+	MaxReplayKeystrokes = 4000
+)
+
+// Keystroke marks
+const (
+	KeyCorrect  = 'c'
+	KeyError    = 'e'
+	KeyRejected = 'r'
+)
+
+// ReplayTrack is one player's keystrokes, stored column by column so a
+// replay stays small. Times are in milliseconds since the keystroke before,
+// the first one since the round started. Marks has a letter per keystroke:
+// c for a key that was right, e for one that was wrong and r for one the
+// server rejected without applying.
+type ReplayTrack struct {
+	PlayerId  string   `json:"-"`
+	Name      string   `json:"name"`
+	Placement int      `json:"placement"`
+	Finished  bool     `json:"finished"`
+	// Milliseconds from the start of the round until they stopped typing
+	TypingTime int64   `json:"typingTime"`
+	Times     []int64  `json:"times"`
+	Keys      []string `json:"keys"`
+	Marks     string   `json:"marks"`
+}
+
+type Replay struct {
+	MatchId    string        `json:"matchId"`
+	GameId     string        `json:"gameId"`
+	// How many rematches the game had had when this was played
+	Rematch    int           `json:"rematch"`
+	Round      int           `json:"round"`
+	TweetId    string        `json:"tweetId"`
+	Tweet      string        `json:"tweet"`
+	TypingMode string        `json:"typingMode"`
+	// How the tweet and keys were normalized
+	Text       text.Options  `json:"text"`
+	StartTime  time.Time     `json:"startTime"`
+	// Milliseconds from the start of the round to its end
+	Duration   int64         `json:"duration"`
+	Tracks     []ReplayTrack `json:"tracks"`
+}
+
+func replayKey(game_id string, rematch int) string {
+	return ReplayPrefix + game_id + ":" + strconv.Itoa(rematch)
+}
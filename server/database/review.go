@@ -0,0 +1,26 @@
+package database
+
+import "time"
+
+// Results that look automated are held back from a player's stats until
+// someone reviews them. Each is kept at Review:<id> and queued, oldest
+// first, in a sorted set at ReviewQueue scored by when it was flagged.
+const (
+	ReviewPrefix   = "Review:"
+	ReviewQueueKey = "ReviewQueue"
+)
+
+type FlaggedResult struct {
+	Id       string     `json:"id"`
+	PlayerId string     `json:"playerId"`
+	Name     string     `json:"name"`
+	// The game, rematch and round, to find its replay
+	GameId   string     `json:"gameId"`
+	Rematch  int        `json:"rematch"`
+	Round    int        `json:"round"`
+	Flags    []string   `json:"flags"`
+	Result   GameResult `json:"result"`
+	// The run to keep as a personal best if the result is approved
+	Run      *Run       `json:"run,omitempty"`
+	Time     time.Time  `json:"time"`
+}
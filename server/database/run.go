@@ -0,0 +1,21 @@
+package database
+
+// The fastest run each registered player has typed of a tweet is kept in a
+// hash at Runs:<tweetId>:<typingMode>, keyed by player id, with the players
+// ranked by speed in a sorted set at the same key plus :Best. Runs are raced
+// against as ghosts.
+const (
+	RunsPrefix = "Runs:"
+	BestSuffix = ":Best"
+)
+
+type Run struct {
+	TweetId    string      `json:"tweetId"`
+	TypingMode string      `json:"typingMode"`
+	Speed      float64     `json:"speed"`
+	Track      ReplayTrack `json:"track"`
+}
+
+func runsKey(tweet_id string, typing_mode string) string {
+	return RunsPrefix + tweet_id + ":" + typing_mode
+}
@@ -0,0 +1,100 @@
+package database
+
+// Store is the persistence layer used by the controllers. RedisStore is
+// used in production and MemoryStore for local development and tests.
+type Store interface {
+	// Players
+	CreatePlayer(subject string, name string, email string, picture string) (string, error)
+	PlayerExists(player_id string) bool
+	// Sign in identities. Subject:<sub> holds the id of the player an
+	// identity provider's subject signs in as, and <player>:Subject the
+	// subject that owns a player.
+	GetSubjectPlayer(subject string) (string, error)
+	LinkSubject(subject string, player_id string) error
+	PlayerPlayedGame(player_id string, result GameResult) error
+	GetPlayerStats(player_id string) map[string]interface{}
+	GetPlayerSelectedKeyboard(player_id string) int
+	GetPlayerKeyboards(player_id string) []PlayerKeyboard
+	ChangePlayerName(player_id string, new_name string) error
+	ChangePlayerKeyboard(player_id string, new_keyboard_id int) error
+	GrantPlayerKeyboard(player_id string, keyboard_id int) error
+
+	// Competitive rating
+	GetPlayerRating(player_id string) (PlayerRating, error)
+	UpdatePlayerRating(player_id string, change RatingChange) error
+	GetRatingHistory(player_id string, count int) []RatingChange
+
+	// Match history
+	SaveMatch(match Match) (string, error)
+	GetMatch(match_id string) (Match, error)
+	GetPlayerMatches(player_id string, offset int, count int) ([]Match, int, error)
+
+	// Replays
+	SaveReplay(replay Replay) error
+	GetReplays(game_id string, rematch int) ([]Replay, error)
+
+	// Ghost runs
+	SavePersonalBest(player_id string, run Run) error
+	GetPersonalBest(tweet_id string, typing_mode string, player_id string) (Run, error)
+	GetTopRun(tweet_id string, typing_mode string) (Run, error)
+
+	// Results held back for review
+	FlagResult(result FlaggedResult) (string, error)
+	GetFlaggedResults(offset int, count int) ([]FlaggedResult, int, error)
+	ResolveFlaggedResult(review_id string) (FlaggedResult, error)
+
+	// Leaderboards
+	GetLeaderboard(board string, window string, offset int, count int) (Leaderboard, error)
+	GetLeaderboardRank(board string, window string, player_id string) (LeaderboardEntry, bool)
+
+	// Games
+	CreateGame(state string, tweet_id string, creator string, max_players int, time_limit int) (string, error)
+	DeleteGame(game_id string) error
+	UpdateGameStatus(game_id string, state string) error
+	AddPlayerToGame(game_id string, player_id string) error
+	RemovePlayerFromGame(game_id string, player_id string) error
+
+	// Global stats
+	IncrementGamesCreated() error
+	IncrementAccountsCreated() error
+}
+
+type PlayerKeyboard struct {
+	KeyboardId int
+	Selected   bool
+}
+
+// Rating history is kept for this many games
+const RatingHistoryLimit = 100
+
+// PlayerRating is a player's competitive rating. Games is 0 for players who
+// haven't played a rated game yet.
+type PlayerRating struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Games      int
+}
+
+// RatingChange is a player's new rating after a game and how it moved. It
+// is also an entry in their rating history.
+type RatingChange struct {
+	GameId     string  `json:"gameId"`
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+	Change     float64 `json:"change"`
+	Placement  int     `json:"placement"`
+	Time       int64   `json:"time"`
+}
+
+// GameResult is what a player's stats are updated with after a game. Speed
+// is net WPM and RawSpeed is gross WPM.
+type GameResult struct {
+	Speed       float64 `json:"speed"`
+	RawSpeed    float64 `json:"rawSpeed"`
+	Accuracy    float64 `json:"accuracy"`
+	Consistency float64 `json:"consistency"`
+	Won         bool    `json:"won"`
+	Points      float64 `json:"points"`
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	uuid "github.com/satori/go.uuid"
+)
+
+// redisTestStore connects to database 15 of REDIS_ADDR, or a local redis,
+// and skips the test when there isn't one
+func redisTestStore(t *testing.T) *RedisStore {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("REDIS_PASS"), DB: 15})
+	if err := client.Ping(Ctx).Err(); err != nil {
+		t.Skipf("no redis at %s: %v", addr, err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client)
+}
+
+// removePlayer deletes a test player and their subject and takes them off
+// the leaderboards
+func removePlayer(store *RedisStore, player_id string) {
+	subject := strings.TrimPrefix(player_id, PlayerPrefix)
+	store.client.Del(Ctx, player_id, player_id + KeyboardsSuffix, player_id + RatingHistorySuffix,
+		player_id + SubjectSuffix, SubjectPrefix + subject)
+	for _, board := range []string{SpeedBoard, PointsBoard, playedBoard, wonBoard, WinRateBoard} {
+		for _, key := range leaderboardKeys(board, time.Now()) {
+			store.client.ZRem(Ctx, key, player_id)
+		}
+	}
+}
+
+func testPlayedGameConcurrently(t *testing.T, store Store) string {
+	subject := "test-" + uuid.NewV4().String()
+	player_id, err := store.CreatePlayer(subject, "test", "test@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 16
+	const games = 25
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < games; j++ {
+				result := GameResult{Speed: 60, RawSpeed: 65, Accuracy: 0.95, Consistency: 80, Points: 3, Won: j % 5 == 0}
+				if err := store.PlayerPlayedGame(player_id, result); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := store.GetPlayerStats(player_id)
+	if played := stats["MatchesPlayed"]; played != workers * games {
+		t.Errorf("MatchesPlayed = %v, want %d", played, workers * games)
+	}
+	if won := stats["MatchesWon"]; won != workers * games / 5 {
+		t.Errorf("MatchesWon = %v, want %d", won, workers * games / 5)
+	}
+	if points := stats["Points"]; points != float64(workers * games * 3) {
+		t.Errorf("Points = %v, want %d", points, workers * games * 3)
+	}
+	return player_id
+}
+
+func TestMemoryPlayedGameConcurrently(t *testing.T) {
+	testPlayedGameConcurrently(t, NewMemoryStore())
+}
+
+func TestRedisPlayedGameConcurrently(t *testing.T) {
+	store := redisTestStore(t)
+	player_id := testPlayedGameConcurrently(t, store)
+	removePlayer(store, player_id)
+}
+
+func testReplaysPerRematch(t *testing.T, store Store) string {
+	game_id := uuid.NewV4().String()[:8]
+	for rematch := 0; rematch < 2; rematch++ {
+		for round := 1; round <= 2; round++ {
+			replay := Replay{MatchId: fmt.Sprintf("%d-%d", rematch, round), GameId: game_id, Rematch: rematch, Round: round}
+			if err := store.SaveReplay(replay); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// A rematch plays the same rounds of the same game again, without
+	// writing over the replays from before it
+	for rematch := 0; rematch < 2; rematch++ {
+		replays, err := store.GetReplays(game_id, rematch)
+		if err != nil || len(replays) != 2 {
+			t.Fatalf("rematch %d: %+v, %v", rematch, replays, err)
+		}
+		for i, replay := range replays {
+			if want := fmt.Sprintf("%d-%d", rematch, i + 1); replay.MatchId != want || replay.Round != i + 1 {
+				t.Errorf("rematch %d round %d is %s, want %s", rematch, replay.Round, replay.MatchId, want)
+			}
+		}
+	}
+
+	if _, err := store.GetReplays(game_id, 2); err != ErrNotFound {
+		t.Errorf("a rematch that wasn't played has %v", err)
+	}
+	return game_id
+}
+
+func TestMemoryReplaysPerRematch(t *testing.T) {
+	testReplaysPerRematch(t, NewMemoryStore())
+}
+
+func TestRedisReplaysPerRematch(t *testing.T) {
+	store := redisTestStore(t)
+	game_id := testReplaysPerRematch(t, store)
+	store.client.Del(Ctx, replayKey(game_id, 0), replayKey(game_id, 1))
+}
+
+func testCreatePlayer(t *testing.T, store Store) []string {
+	subject := "test-" + uuid.NewV4().String()
+	player_id, err := store.CreatePlayer(subject, "test", "test@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked, err := store.GetSubjectPlayer(subject); err != nil || linked != player_id {
+		t.Errorf("subject is linked to %q, %v, want %q", linked, err, player_id)
+	}
+	if !store.PlayerExists(player_id) {
+		t.Error("the player wasn't created")
+	}
+
+	// Creating the player again doesn't reset them
+	if err := store.PlayerPlayedGame(player_id, GameResult{Speed: 60, Points: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreatePlayer(subject, "again", "", ""); err != ErrLinked {
+		t.Errorf("created the player twice: %v", err)
+	}
+	if stats := store.GetPlayerStats(player_id); stats["Points"] != 3.0 {
+		t.Errorf("second create left %v", stats)
+	}
+
+	// A player whose id is taken by an old account isn't created or linked
+	taken := "test-" + uuid.NewV4().String()
+	other, err := store.CreatePlayer(taken + "-other", "other", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.LinkSubject(taken, PlayerPrefix + taken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreatePlayer(taken, "taken", "", ""); err != ErrLinked {
+		t.Errorf("created a player whose id is owned: %v", err)
+	}
+	if store.PlayerExists(PlayerPrefix + taken) {
+		t.Error("a player was created for a taken id")
+	}
+
+	return []string{player_id, other}
+}
+
+func TestMemoryCreatePlayer(t *testing.T) {
+	testCreatePlayer(t, NewMemoryStore())
+}
+
+func TestRedisCreatePlayer(t *testing.T) {
+	store := redisTestStore(t)
+	for _, player_id := range testCreatePlayer(t, store) {
+		removePlayer(store, player_id)
+	}
+}
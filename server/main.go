@@ -1,16 +1,35 @@
 package main
 
 import (
+	"log"
 	"net/http"
 	"server/config"
 	"server/controller"
+	"server/database"
 	"server/middleware"
+	"server/oidc"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 )
 
 func main() {	
+	database.Open()
+	if err := controller.LoadTweets("users.json", "tweets.json"); err != nil {
+		log.Fatal(err)
+	}
+
+	conf := config.Conf
+	if conf.OIDCIssuer == "" || conf.OIDCAudience == "" || conf.OIDCJWKS == "" {
+		log.Println("OIDC isn't configured, sign in is disabled")
+	} else {
+		verifier, err := oidc.NewVerifier(conf.OIDCIssuer, conf.OIDCAudience, conf.OIDCJWKS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		controller.IdTokens = verifier
+	}
+
 	headersOk := handlers.AllowedHeaders([]string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
 	originsOk := handlers.AllowedOrigins([]string{
@@ -25,6 +44,7 @@ func main() {
 	r.HandleFunc("/signin", controller.SigninHandler).Methods("POST")
 	r.HandleFunc("/joinGame", controller.JoinGameHandler).Methods("Get")
 	r.HandleFunc("/keyboards", controller.GetAllKeyboardsHandler).Methods("GET")
+	r.HandleFunc("/protocol", controller.GetProtocolSchemaHandler).Methods("GET")
 	
 	// Requires player context
 	r.Handle("/createGame", middleware.PlayerCtx(
@@ -35,6 +55,38 @@ func main() {
 		http.HandlerFunc(controller.JoinRandomGameHandler)),
 	).Methods("POST")
 
+	r.Handle("/joinRankedGame", middleware.PlayerCtx(
+		http.HandlerFunc(controller.JoinRankedGameHandler)),
+	).Methods("POST")
+
+	r.Handle("/createGhostGame", middleware.PlayerCtx(
+		http.HandlerFunc(controller.CreateGhostGameHandler)),
+	).Methods("POST")
+
+	r.Handle("/leaderboard", middleware.PlayerCtx(
+		http.HandlerFunc(controller.GetLeaderboardHandler)),
+	).Methods("GET")
+
+	r.Handle("/matches", middleware.PlayerCtx(
+		http.HandlerFunc(controller.GetMatchesHandler)),
+	).Methods("GET")
+
+	r.Handle("/matches/{id}", middleware.PlayerCtx(
+		http.HandlerFunc(controller.GetMatchHandler)),
+	).Methods("GET")
+
+	r.Handle("/replays/{gameId}", middleware.PlayerCtx(
+		http.HandlerFunc(controller.GetReplaysHandler)),
+	).Methods("GET")
+
+	r.Handle("/reviews", middleware.PlayerCtx(
+		http.HandlerFunc(controller.GetReviewsHandler)),
+	).Methods("GET")
+
+	r.Handle("/reviews/{id}", middleware.PlayerCtx(
+		http.HandlerFunc(controller.ResolveReviewHandler)),
+	).Methods("POST")
+
 	r.Handle("/playerStats", middleware.PlayerCtx(
 		http.HandlerFunc(controller.GetPlayerStatsHandler)),
 	).Methods("GET")
@@ -0,0 +1,287 @@
+package matchmaking
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Players wait in the pool bucketed by rating. Each step they are put in
+// an open game close to their rating or grouped with other waiting players
+// for a new one. The longer someone waits the wider the range of ratings
+// they can be matched with. Windows are in WPM and multiplied by the
+// pool's scale for other kinds of rating.
+const (
+	BucketSize = 10.0
+	BaseWindow = 10.0
+	// The window widens by WindowGrowth every second a player waits
+	WindowGrowth = 5.0
+	MaxWindow    = 60.0
+	// Players who haven't found anyone by then get a game of their own,
+	// which others can join while it's open
+	MaxWait    = 8 * time.Second
+	MinPlayers = 2
+)
+
+// Window is how far from their rating a player will be matched after
+// waiting for the given time
+func Window(waited time.Duration) float64 {
+	return math.Min(BaseWindow + WindowGrowth * waited.Seconds(), MaxWindow)
+}
+
+func (p *Pool) bucket(rating float64) int {
+	return int(math.Floor(rating / (BucketSize * p.scale)))
+}
+
+type Ticket struct {
+	PlayerId string
+	Rating   float64
+	Joined   time.Time
+}
+
+// Room is a game that is still taking players
+type Room struct {
+	GameId  string
+	Ratings []float64
+	Free    int
+}
+
+func (r *Room) Rating() float64 {
+	return mean(r.Ratings)
+}
+
+// Match puts players in a game. GameId is empty when they need a new one.
+// Ratings are of everyone in the game, including players already there.
+type Match struct {
+	GameId  string
+	Tickets []*Ticket
+	Ratings []float64
+}
+
+// Spread is the gap between the best and worst rated player in the game
+func (m Match) Spread() float64 {
+	if len(m.Ratings) == 0 {
+		return 0
+	}
+
+	low, high := m.Ratings[0], m.Ratings[0]
+	for _, rating := range m.Ratings {
+		low = math.Min(low, rating)
+		high = math.Max(high, rating)
+	}
+	return high - low
+}
+
+type Pool struct {
+	mu       sync.Mutex
+	capacity int
+	scale    float64
+	buckets  map[int][]*Ticket
+	rooms    map[string]*Room
+}
+
+// NewPool makes a pool for games of up to capacity players. Ratings are
+// scale times the size of a WPM.
+func NewPool(capacity int, scale float64) *Pool {
+	return &Pool{
+		capacity: capacity,
+		scale: scale,
+		buckets: make(map[int][]*Ticket),
+		rooms: make(map[string]*Room),
+	}
+}
+
+func (p *Pool) Add(ticket *Ticket) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b := p.bucket(ticket.Rating)
+	p.buckets[b] = append(p.buckets[b], ticket)
+}
+
+// Remove takes a player who gave up out of the pool
+func (p *Pool) Remove(ticket *Ticket) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.remove(ticket)
+}
+
+func (p *Pool) remove(ticket *Ticket) {
+	b := p.bucket(ticket.Rating)
+	for i := range p.buckets[b] {
+		if p.buckets[b][i] == ticket {
+			p.buckets[b] = append(p.buckets[b][:i], p.buckets[b][i+1:]...)
+			break
+		}
+	}
+
+	if len(p.buckets[b]) == 0 {
+		delete(p.buckets, b)
+	}
+}
+
+// Open lets players be matched into a game until it fills up or is closed
+func (p *Pool) Open(game_id string, ratings []float64, free int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if free > 0 {
+		p.rooms[game_id] = &Room{GameId: game_id, Ratings: ratings, Free: free}
+	}
+}
+
+// Close stops matching players into a game, e.g. once it has started
+func (p *Pool) Close(game_id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.rooms, game_id)
+}
+
+// Waiting returns how many players are in the pool
+func (p *Pool) Waiting() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	count := 0
+	for b := range p.buckets {
+		count += len(p.buckets[b])
+	}
+	return count
+}
+
+// Step matches whoever it can, longest waiting first, and takes them out
+// of the pool
+func (p *Pool) Step(now time.Time) []Match {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var tickets []*Ticket
+	for b := range p.buckets {
+		tickets = append(tickets, p.buckets[b]...)
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].Joined.Before(tickets[j].Joined)
+	})
+
+	var matches []Match
+	matched := make(map[*Ticket]bool)
+
+	for _, ticket := range tickets {
+		if matched[ticket] {
+			continue
+		}
+
+		waited := now.Sub(ticket.Joined)
+		window := Window(waited) * p.scale
+
+		if room := p.findRoom(ticket, window); room != nil {
+			room.Ratings = append(room.Ratings, ticket.Rating)
+			room.Free -= 1
+			if room.Free == 0 {
+				delete(p.rooms, room.GameId)
+			}
+
+			matched[ticket] = true
+			matches = append(matches, Match{
+				GameId: room.GameId,
+				Tickets: []*Ticket{ticket},
+				Ratings: append([]float64{}, room.Ratings...),
+			})
+			continue
+		}
+
+		group := append([]*Ticket{ticket}, p.findGroup(ticket, window, matched)...)
+		if len(group) < MinPlayers && waited < MaxWait {
+			continue
+		}
+
+		match := Match{Tickets: group}
+		for _, member := range group {
+			matched[member] = true
+			match.Ratings = append(match.Ratings, member.Rating)
+		}
+		matches = append(matches, match)
+	}
+
+	for ticket := range matched {
+		p.remove(ticket)
+	}
+
+	return matches
+}
+
+// findRoom returns the open game closest to the player's rating that they
+// fit in without the game's spread going over the window
+func (p *Pool) findRoom(ticket *Ticket, window float64) *Room {
+	var best *Room
+	best_gap := math.Inf(1)
+
+	for _, room := range p.rooms {
+		joined := Match{Ratings: append([]float64{ticket.Rating}, room.Ratings...)}
+		if joined.Spread() > window {
+			continue
+		}
+
+		gap := math.Abs(room.Rating() - ticket.Rating)
+
+		if gap < best_gap {
+			best = room
+			best_gap = gap
+		}
+	}
+
+	return best
+}
+
+// findGroup returns the waiting players closest to the ticket's rating,
+// enough to fill a game. Everyone in the group is within the window of
+// each other, not just of the ticket.
+func (p *Pool) findGroup(ticket *Ticket, window float64, matched map[*Ticket]bool) []*Ticket {
+	var candidates []*Ticket
+
+	for b := p.bucket(ticket.Rating - window); b <= p.bucket(ticket.Rating + window); b++ {
+		for _, other := range p.buckets[b] {
+			if other == ticket || matched[other] {
+				continue
+			}
+			if math.Abs(other.Rating - ticket.Rating) <= window {
+				candidates = append(candidates, other)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return math.Abs(candidates[i].Rating - ticket.Rating) < math.Abs(candidates[j].Rating - ticket.Rating)
+	})
+
+	var group []*Ticket
+	low, high := ticket.Rating, ticket.Rating
+	for _, other := range candidates {
+		if len(group) == p.capacity - 1 {
+			break
+		}
+		if math.Max(high, other.Rating) - math.Min(low, other.Rating) > window {
+			continue
+		}
+
+		group = append(group, other)
+		low = math.Min(low, other.Rating)
+		high = math.Max(high, other.Rating)
+	}
+	return group
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,101 @@
+package matchmaking
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const (
+	simulatedCapacity = 6
+	simulatedStep     = 500 * time.Millisecond
+	simulatedSteps    = 240
+	// Games stop taking players this long after they are created
+	simulatedOpen = 5 * time.Second
+)
+
+// TestPoolStep runs a couple of minutes of players joining the pool, the
+// way the matchmaking loop steps it, and checks nobody is put in a game
+// wider than the window of whoever the match was made for
+func TestPoolStep(t *testing.T) {
+	for _, scale := range []float64{1, 10} {
+		t.Run(fmt.Sprintf("scale %v", scale), func(t *testing.T) {
+			simulatePool(t, scale)
+		})
+	}
+}
+
+func simulatePool(t *testing.T, scale float64) {
+	random := rand.New(rand.NewSource(1))
+	pool := NewPool(simulatedCapacity, scale)
+	now := time.Unix(0, 0)
+
+	opened := make(map[string]time.Time)
+	joined, matched, games := 0, 0, 0
+	max_wait := time.Duration(0)
+
+	for step := 0; step < simulatedSteps; step++ {
+		now = now.Add(simulatedStep)
+
+		// Busy and quiet spells, with most players between 20 and 80 WPM
+		arrivals := random.Intn(4)
+		if step % 60 >= 40 {
+			arrivals = random.Intn(2)
+		}
+		for i := 0; i < arrivals; i++ {
+			rating := (DefaultRating + random.NormFloat64() * 20) * scale
+			if rating < 0 {
+				rating = 0
+			}
+			pool.Add(&Ticket{PlayerId: fmt.Sprintf("Player:%d", joined), Rating: rating, Joined: now})
+			joined += 1
+		}
+
+		for game_id, created := range opened {
+			if now.Sub(created) >= simulatedOpen {
+				pool.Close(game_id)
+				delete(opened, game_id)
+			}
+		}
+
+		for _, match := range pool.Step(now) {
+			waited := now.Sub(match.Tickets[0].Joined)
+			window := Window(waited) * scale
+			t.Logf("%6v: %d joined %-8q waited %-5v spread %6.1f window %6.1f",
+				now.Sub(time.Unix(0, 0)), len(match.Tickets), match.GameId, waited, match.Spread(), window)
+
+			if match.Spread() > window {
+				t.Errorf("%v: spread %.1f is wider than the window %.1f after %v", now, match.Spread(), window, waited)
+			}
+			if len(match.Ratings) > simulatedCapacity {
+				t.Errorf("%v: %d players in a game of %d", now, len(match.Ratings), simulatedCapacity)
+			}
+
+			for _, ticket := range match.Tickets {
+				if ticket_waited := now.Sub(ticket.Joined); ticket_waited > max_wait {
+					max_wait = ticket_waited
+				}
+			}
+			matched += len(match.Tickets)
+
+			if match.GameId == "" {
+				games += 1
+				game_id := fmt.Sprintf("Game:%d", games)
+				pool.Open(game_id, match.Ratings, simulatedCapacity - len(match.Tickets))
+				opened[game_id] = now
+			}
+		}
+	}
+
+	t.Logf("%d joined, %d matched into %d games, longest wait %v", joined, matched, games, max_wait)
+	if matched + pool.Waiting() != joined {
+		t.Errorf("%d matched and %d waiting, but %d joined", matched, pool.Waiting(), joined)
+	}
+	if max_wait > MaxWait + simulatedStep {
+		t.Errorf("a player waited %v", max_wait)
+	}
+	if matched == 0 || games == 0 {
+		t.Error("nobody was matched")
+	}
+}
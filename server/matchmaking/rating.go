@@ -0,0 +1,32 @@
+package matchmaking
+
+import "math"
+
+// Ratings are on the same scale as typing speed, so a player rated 60 types
+// about 60 WPM and wins about as often as expected for that.
+const (
+	DefaultRating = 40.0
+	// Games played before a player's own record counts in full
+	ProvisionalGames = 5
+	// How far winning more or less often than expected moves a rating, as a
+	// fraction of it
+	WinRateWeight   = 0.5
+	ExpectedWinRate = 0.25
+)
+
+// Rating works out a player's rating from their average speed and how often
+// they finished first. Players with few games stay close to DefaultRating.
+func Rating(avg_speed float64, matches_played int, matches_won int) float64 {
+	if matches_played <= 0 {
+		return DefaultRating
+	}
+
+	played := float64(matches_played)
+	confidence := played / (played + ProvisionalGames)
+	win_rate := float64(matches_won) / played
+
+	rating := DefaultRating + (avg_speed - DefaultRating) * confidence
+	rating *= 1 + WinRateWeight * (win_rate - ExpectedWinRate) * confidence
+
+	return math.Max(rating, 0)
+}
@@ -0,0 +1,127 @@
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Sign in takes an OpenID Connect ID token from the identity provider. It
+// has to be signed by one of the provider's keys, issued by the configured
+// issuer for our client id and not expired. The key set is read from a URL,
+// or a file for tests and offline development, and read again when a token
+// names a key we don't have, so keys can be rotated.
+const (
+	// Allowed difference between our clock and the provider's
+	Leeway = time.Minute
+	// The key set isn't read again more often than this
+	RefreshInterval = time.Minute
+)
+
+// Only asymmetric algorithms; the keys are public
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type Claims struct {
+	Email         string      `json:"email"`
+	// Some providers send this as a string
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+	Picture       string      `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// Verified is whether the provider has checked the player owns the email
+func (c *Claims) Verified() bool {
+	return c.EmailVerified == true || c.EmailVerified == "true"
+}
+
+type Verifier struct {
+	issuer   string
+	audience string
+	source   string
+
+	mu      sync.Mutex
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+// NewVerifier reads the key set from source, a URL or a file path
+func NewVerifier(issuer string, audience string, source string) (*Verifier, error) {
+	v := &Verifier{issuer: issuer, audience: audience, source: source}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.refresh(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *Verifier) refresh() error {
+	data, err := readJWKS(v.source)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.keys = keys
+	v.fetched = time.Now()
+	return nil
+}
+
+// key returns the public key a token was signed with
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	if !ok && time.Since(v.fetched) >= RefreshInterval {
+		if err := v.refresh(); err != nil {
+			return nil, err
+		}
+		key, ok = v.keys[kid]
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// Verify checks an ID token and returns its claims
+func (v *Verifier) Verify(token_str string) (*Claims, error) {
+	// Times are checked below, with leeway
+	parser := jwt.NewParser(jwt.WithValidMethods(signingMethods), jwt.WithoutClaimsValidation())
+
+	claims := &Claims{}
+	if _, err := parser.ParseWithClaims(token_str, claims, v.key); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case !claims.VerifyIssuer(v.issuer, true):
+		return nil, errors.New("token is from another issuer")
+	case !claims.VerifyAudience(v.audience, true):
+		return nil, errors.New("token is for another audience")
+	case claims.ExpiresAt == nil || now.After(claims.ExpiresAt.Add(Leeway)):
+		return nil, errors.New("token has expired")
+	case claims.NotBefore != nil && now.Add(Leeway).Before(claims.NotBefore.Time):
+		return nil, errors.New("token is not valid yet")
+	case claims.IssuedAt != nil && now.Add(Leeway).Before(claims.IssuedAt.Time):
+		return nil, errors.New("token was issued in the future")
+	case claims.Subject == "":
+		return nil, errors.New("token has no subject")
+	}
+
+	return claims, nil
+}
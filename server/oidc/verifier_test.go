@@ -0,0 +1,177 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "tweet-typer"
+)
+
+func encode(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// writeJWKS writes the public halves of the keys to a key set file
+func writeJWKS(t *testing.T, path string, keys map[string]interface{}) {
+	var set jwks
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			set.Keys = append(set.Keys, jwk{Kty: "RSA", Kid: kid, Use: "sig", N: encode(key.N), E: encode(big.NewInt(int64(key.E)))})
+		case *ecdsa.PrivateKey:
+			set.Keys = append(set.Keys, jwk{Kty: "EC", Kid: kid, Crv: "P-256", X: encode(key.X), Y: encode(key.Y)})
+		}
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// validClaims are claims that pass, for the tests to break one at a time
+func validClaims(now time.Time) Claims {
+	return Claims{
+		Email: "player@example.com",
+		EmailVerified: "true",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer: testIssuer,
+			Audience: jwt.ClaimStrings{testAudience},
+			Subject: "1234567890",
+			IssuedAt: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims Claims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	token_str, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token_str
+}
+
+func TestVerify(t *testing.T) {
+	rsa_key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec_key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other_key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]interface{}{"rsa": rsa_key, "ec": ec_key})
+	verifier, err := NewVerifier(testIssuer, testAudience, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	with := func(change func(claims *Claims)) Claims {
+		claims := validClaims(now)
+		change(&claims)
+		return claims
+	}
+	rs256 := func(claims Claims) string { return sign(t, jwt.SigningMethodRS256, "rsa", rsa_key, claims) }
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"rsa", rs256(validClaims(now)), true},
+		{"ec", sign(t, jwt.SigningMethodES256, "ec", ec_key, validClaims(now)), true},
+		{"one of several audiences", rs256(with(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other", testAudience} })), true},
+
+		{"bad signature", sign(t, jwt.SigningMethodRS256, "rsa", other_key, validClaims(now)), false},
+		{"key of the wrong type", sign(t, jwt.SigningMethodRS256, "ec", rsa_key, validClaims(now)), false},
+		{"unknown key", sign(t, jwt.SigningMethodRS256, "rotated", other_key, validClaims(now)), false},
+		{"no key id", sign(t, jwt.SigningMethodRS256, "", rsa_key, validClaims(now)), false},
+		{"hs256", sign(t, jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims(now)), false},
+		{"none", sign(t, jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType, validClaims(now)), false},
+		{"garbage", "not.a.token", false},
+
+		{"wrong issuer", rs256(with(func(c *Claims) { c.Issuer = "https://evil.example.com" })), false},
+		{"no issuer", rs256(with(func(c *Claims) { c.Issuer = "" })), false},
+		{"wrong audience", rs256(with(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other"} })), false},
+		{"no audience", rs256(with(func(c *Claims) { c.Audience = nil })), false},
+		{"no subject", rs256(with(func(c *Claims) { c.Subject = "" })), false},
+
+		// Expiry and start times get a minute either way for clock skew
+		{"expired", rs256(with(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour)) })), false},
+		{"expired within the leeway", rs256(with(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-Leeway + 5 * time.Second)) })), true},
+		{"expired past the leeway", rs256(with(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-Leeway - 5 * time.Second)) })), false},
+		{"no expiry", rs256(with(func(c *Claims) { c.ExpiresAt = nil })), false},
+		{"not valid yet", rs256(with(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Hour)) })), false},
+		{"not valid yet within the leeway", rs256(with(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(Leeway - 5 * time.Second)) })), true},
+		{"issued in the future", rs256(with(func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(now.Add(time.Hour)) })), false},
+	}
+
+	for _, test := range tests {
+		claims, err := verifier.Verify(test.token)
+		if test.valid && (err != nil || claims.Subject != "1234567890" || !claims.Verified()) {
+			t.Errorf("%s: got %+v, %v", test.name, claims, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: token was accepted", test.name)
+		}
+	}
+}
+
+// A token signed with a key the verifier hasn't seen makes it read the key
+// set again, but not more often than RefreshInterval
+func TestVerifyRotatedKey(t *testing.T) {
+	old_key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	new_key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]interface{}{"old": old_key})
+	verifier, err := NewVerifier(testIssuer, testAudience, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeJWKS(t, path, map[string]interface{}{"new": new_key})
+	token := sign(t, jwt.SigningMethodRS256, "new", new_key, validClaims(time.Now()))
+	if _, err := verifier.Verify(token); err == nil {
+		t.Error("the key set was read again straight away")
+	}
+
+	verifier.fetched = time.Now().Add(-RefreshInterval)
+	if _, err := verifier.Verify(token); err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package protocol
+
+import "server/text"
+
+// Messages sent by players
+
+type RegisterPlayerAction struct {
+	Name string `json:"name"`
+}
+
+func (m *RegisterPlayerAction) Action() string { return "registerPlayer" }
+
+// SpectateAction joins a game as a spectator, in any state
+type SpectateAction struct {
+	Name string `json:"name"`
+}
+
+func (m *SpectateAction) Action() string { return "spectate" }
+
+type StartCountdownAction struct{}
+
+func (m *StartCountdownAction) Action() string { return "startCountdown" }
+
+type PlayerMoveAction struct {
+	Key string `json:"key"`
+}
+
+func (m *PlayerMoveAction) Action() string { return "playerMove" }
+
+func (m *PlayerMoveAction) Validate() *Error {
+	if m.Key == "" {
+		return NewError(InvalidData, "key is required")
+	}
+	if !text.ValidKey(m.Key) {
+		return NewError(InvalidData, "key must be a single character or a named key")
+	}
+	return nil
+}
+
+// Keystroke is a key typed Offset milliseconds after the client saw the
+// game start
+type Keystroke struct {
+	Key    string `json:"key"`
+	Offset int64  `json:"offset"`
+}
+
+const MaxKeysPerBatch = 100
+
+type PlayerKeysAction struct {
+	Keys []Keystroke `json:"keys"`
+}
+
+func (m *PlayerKeysAction) Action() string { return "playerKeys" }
+
+func (m *PlayerKeysAction) Validate() *Error {
+	if len(m.Keys) == 0 || len(m.Keys) > MaxKeysPerBatch {
+		return NewError(InvalidData, "keys must hold between 1 and 100 keystrokes")
+	}
+
+	for i := range m.Keys {
+		if m.Keys[i].Key == "" {
+			return NewError(InvalidData, "key is required")
+		}
+		if !text.ValidKey(m.Keys[i].Key) {
+			return NewError(InvalidData, "key must be a single character or a named key")
+		}
+		if m.Keys[i].Offset < 0 || (i > 0 && m.Keys[i].Offset < m.Keys[i - 1].Offset) {
+			return NewError(InvalidData, "offsets must be positive and in order")
+		}
+	}
+	return nil
+}
+
+type PlayerGuessAction struct {
+	Guess string `json:"guess"`
+}
+
+func (m *PlayerGuessAction) Action() string { return "playerGuess" }
+
+type PingAction struct{}
+
+func (m *PingAction) Action() string { return "ping" }
+
+type ResumeAction struct {
+	Token string `json:"token"`
+}
+
+func (m *ResumeAction) Action() string { return "resume" }
+
+func (m *ResumeAction) Validate() *Error {
+	if m.Token == "" {
+		return NewError(InvalidData, "token is required")
+	}
+	return nil
+}
+
+// UpdateSettingsAction is sent by the host to change the game's settings
+// while it is still in the lobby
+type UpdateSettingsAction struct {
+	Settings GameSettings `json:"settings"`
+}
+
+func (m *UpdateSettingsAction) Action() string { return "updateSettings" }
+
+// RematchAction is sent by the host after a private game has finished to
+// play again with the same players
+type RematchAction struct{}
+
+func (m *RematchAction) Action() string { return "rematch" }
+
+// Host controls for private games. Players are picked by their public id.
+
+type KickPlayerAction struct {
+	PlayerId string `json:"playerId"`
+}
+
+func (m *KickPlayerAction) Action() string { return "kickPlayer" }
+
+func (m *KickPlayerAction) Validate() *Error {
+	if m.PlayerId == "" {
+		return NewError(InvalidData, "playerId is required")
+	}
+	return nil
+}
+
+type TransferHostAction struct {
+	PlayerId string `json:"playerId"`
+}
+
+func (m *TransferHostAction) Action() string { return "transferHost" }
+
+func (m *TransferHostAction) Validate() *Error {
+	if m.PlayerId == "" {
+		return NewError(InvalidData, "playerId is required")
+	}
+	return nil
+}
+
+type LockRoomAction struct {
+	Locked bool `json:"locked"`
+}
+
+func (m *LockRoomAction) Action() string { return "lockRoom" }
+
+// ReadyAction marks the player ready, or not, for the host's ready check
+type ReadyAction struct {
+	Ready bool `json:"ready"`
+}
+
+func (m *ReadyAction) Action() string { return "ready" }
+
+func init() {
+	registerClientMessage(func() ClientMessage { return &RegisterPlayerAction{} })
+	registerClientMessage(func() ClientMessage { return &SpectateAction{} })
+	registerClientMessage(func() ClientMessage { return &StartCountdownAction{} })
+	registerClientMessage(func() ClientMessage { return &PlayerMoveAction{} })
+	registerClientMessage(func() ClientMessage { return &PlayerKeysAction{} })
+	registerClientMessage(func() ClientMessage { return &PlayerGuessAction{} })
+	registerClientMessage(func() ClientMessage { return &PingAction{} })
+	registerClientMessage(func() ClientMessage { return &ResumeAction{} })
+	registerClientMessage(func() ClientMessage { return &UpdateSettingsAction{} })
+	registerClientMessage(func() ClientMessage { return &RematchAction{} })
+	registerClientMessage(func() ClientMessage { return &KickPlayerAction{} })
+	registerClientMessage(func() ClientMessage { return &TransferHostAction{} })
+	registerClientMessage(func() ClientMessage { return &LockRoomAction{} })
+	registerClientMessage(func() ClientMessage { return &ReadyAction{} })
+}
@@ -0,0 +1,41 @@
+package protocol
+
+type ErrorCode string
+
+const (
+	BadMessage          ErrorCode = "BAD_MESSAGE"
+	UnsupportedVersion  ErrorCode = "UNSUPPORTED_VERSION"
+	UnknownAction       ErrorCode = "UNKNOWN_ACTION"
+	InvalidData         ErrorCode = "INVALID_DATA"
+	GameFull            ErrorCode = "GAME_FULL"
+	GameAlreadyStarted  ErrorCode = "GAME_ALREADY_STARTED"
+	CountdownNotAllowed ErrorCode = "COUNTDOWN_NOT_ALLOWED"
+	SessionExpired      ErrorCode = "SESSION_EXPIRED"
+	InvalidKeystrokes   ErrorCode = "INVALID_KEYSTROKES"
+	InvalidSettings     ErrorCode = "INVALID_SETTINGS"
+	NotHost             ErrorCode = "NOT_HOST"
+	Kicked              ErrorCode = "KICKED"
+	RoomLocked          ErrorCode = "ROOM_LOCKED"
+	Spectating          ErrorCode = "SPECTATING"
+	SignInRequired      ErrorCode = "SIGN_IN_REQUIRED"
+)
+
+// Error is sent to a player when one of their messages is rejected
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *Error) Action() string { return "error" }
+
+func init() {
+	registerServerMessage(&Error{})
+}
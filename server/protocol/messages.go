@@ -0,0 +1,254 @@
+package protocol
+
+import "server/text"
+
+// Messages sent by the server
+
+// GameSettings can be chosen for private games. Times are in seconds and
+// tweet lengths in graphemes; a MaxTweetLength of 0 means no limit. No
+// categories means tweets from any author, and no text options means
+// text.DefaultOptions.
+type GameSettings struct {
+	MaxPlayers      int      `json:"maxPlayers"`
+	TimeLimit       int      `json:"timeLimit"`
+	GuessTimeLimit  int      `json:"guessTimeLimit"`
+	Countdown       int      `json:"countdown"`
+	AuthorChoices   int      `json:"authorChoices"`
+	MinTweetLength  int      `json:"minTweetLength"`
+	MaxTweetLength  int      `json:"maxTweetLength"`
+	Categories      []string `json:"categories"`
+	GuessingEnabled bool     `json:"guessingEnabled"`
+	TypingMode      string   `json:"typingMode"`
+	ScoringRule     string   `json:"scoringRule"`
+	Rounds          int      `json:"rounds"`
+	Text            *text.Options `json:"text,omitempty"`
+}
+
+type SendSettingsMessage GameSettings
+
+func (m *SendSettingsMessage) Action() string { return "sendSettings" }
+
+type SendGameTypeMessage string
+
+func (m SendGameTypeMessage) Action() string { return "sendGameType" }
+
+type PlayerInfo struct {
+	Id               string  `json:"id"`
+	Name             string  `json:"name"`
+	KeyboardLink     string  `json:"keyboardLink"`
+	Speed            float64 `json:"speed"`
+	Points           float64 `json:"points"`
+	State            string  `json:"state"`
+	IsUser           bool    `json:"isUser"`
+	Placement        int     `json:"placement"`
+	IsCreator        bool    `json:"isCreator"`
+	CorrectAnswers   int     `json:"correctAnswers"`
+	IncorrectAnswers int     `json:"incorrectAnswers"`
+	CurrentLetterIdx int     `json:"currentLetterIdx"`
+	Connection       string  `json:"connection"`
+	Ready            bool    `json:"ready"`
+	// Set for ghosts to where their run came from
+	Ghost            string  `json:"ghost,omitempty"`
+}
+
+type SendActivePlayersMessage []PlayerInfo
+
+func (m SendActivePlayersMessage) Action() string { return "sendActivePlayers" }
+
+type SpectatorInfo struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	IsUser bool   `json:"isUser"`
+}
+
+// SendSpectatorsMessage lists who is watching. It is sent alongside the
+// roster of players.
+type SendSpectatorsMessage []SpectatorInfo
+
+func (m SendSpectatorsMessage) Action() string { return "sendSpectators" }
+
+// PlayerDelta holds only the fields of a player that changed since the last
+// update
+type PlayerDelta struct {
+	Id               string   `json:"id"`
+	Speed            *float64 `json:"speed,omitempty"`
+	Points           *float64 `json:"points,omitempty"`
+	State            *string  `json:"state,omitempty"`
+	Placement        *int     `json:"placement,omitempty"`
+	CorrectAnswers   *int     `json:"correctAnswers,omitempty"`
+	IncorrectAnswers *int     `json:"incorrectAnswers,omitempty"`
+	CurrentLetterIdx *int     `json:"currentLetterIdx,omitempty"`
+	Connection       *string  `json:"connection,omitempty"`
+	Ready            *bool    `json:"ready,omitempty"`
+}
+
+type PlayerUpdatesMessage []PlayerDelta
+
+func (m PlayerUpdatesMessage) Action() string { return "playerUpdates" }
+
+type StartCountdownMessage struct {
+	State string `json:"state"`
+	Clock int    `json:"clock"`
+}
+
+func (m *StartCountdownMessage) Action() string { return "startCountdown" }
+
+type StartGameMessage struct {
+	State         string   `json:"state"`
+	Tweet         string   `json:"tweet"`
+	Graphemes     []string `json:"graphemes"`
+	TypingMode    string   `json:"typingMode"`
+	ScoringRule   string   `json:"scoringRule"`
+	AuthorChoices []string `json:"authorChoices"`
+}
+
+func (m *StartGameMessage) Action() string { return "startGame" }
+
+// PlayerResult is how fast and accurately a player typed. Speeds are in
+// words per minute and samples are the speed during each second.
+type PlayerResult struct {
+	Id                string    `json:"id"`
+	GrossWPM          float64   `json:"grossWpm"`
+	NetWPM            float64   `json:"netWpm"`
+	Accuracy          float64   `json:"accuracy"`
+	Samples           []float64 `json:"samples"`
+	Consistency       float64   `json:"consistency"`
+	CorrectedErrors   int       `json:"correctedErrors"`
+	UncorrectedErrors int       `json:"uncorrectedErrors"`
+	// The words the player made mistakes in
+	WordErrors        []WordError `json:"wordErrors"`
+	// Competitive rating after the game, for registered players in public
+	// games
+	Rating            float64   `json:"rating,omitempty"`
+	RatingChange      float64   `json:"ratingChange,omitempty"`
+	// Results that look automated are voided, or held back from the
+	// player's stats until they are reviewed
+	Voided            bool      `json:"voided,omitempty"`
+	UnderReview       bool      `json:"underReview,omitempty"`
+}
+
+// WordError is how many mistakes a player made in one word of the tweet.
+// Word counts words from 0.
+type WordError struct {
+	Word   int    `json:"word"`
+	Text   string `json:"text"`
+	Errors int    `json:"errors"`
+}
+
+// MatchStanding is a player's place in a match so far
+type MatchStanding struct {
+	Id        string  `json:"id"`
+	Name      string  `json:"name"`
+	Points    float64 `json:"points"`
+	RoundsWon int     `json:"roundsWon"`
+	Placement int     `json:"placement"`
+}
+
+// StartFinishMessage is sent when the last round ends. The scoreboard is the
+// final podium of the match.
+type StartFinishMessage struct {
+	State        string          `json:"state"`
+	Author       string          `json:"author"`
+	AuthorHandle string          `json:"authorHandle"`
+	Round        int             `json:"round"`
+	Rounds       int             `json:"rounds"`
+	Results      []PlayerResult  `json:"results"`
+	Scoreboard   []MatchStanding `json:"scoreboard"`
+}
+
+func (m *StartFinishMessage) Action() string { return "startFinish" }
+
+// RoundFinishMessage is sent when a round other than the last ends. The next
+// round counts down on its own after Clock seconds.
+type RoundFinishMessage struct {
+	State        string          `json:"state"`
+	Author       string          `json:"author"`
+	AuthorHandle string          `json:"authorHandle"`
+	Round        int             `json:"round"`
+	Rounds       int             `json:"rounds"`
+	Clock        int             `json:"clock"`
+	Results      []PlayerResult  `json:"results"`
+	Scoreboard   []MatchStanding `json:"scoreboard"`
+}
+
+func (m *RoundFinishMessage) Action() string { return "roundFinish" }
+
+// RematchMessage tells everyone the room is back in the lobby
+type RematchMessage struct {
+	State string `json:"state"`
+}
+
+func (m *RematchMessage) Action() string { return "rematch" }
+
+type RoomLockedMessage struct {
+	Locked bool `json:"locked"`
+}
+
+func (m *RoomLockedMessage) Action() string { return "roomLocked" }
+
+// ReadyCheckMessage asks everyone to mark themselves ready. The countdown
+// starts once they all have.
+type ReadyCheckMessage struct{}
+
+func (m *ReadyCheckMessage) Action() string { return "readyCheck" }
+
+type TickMessage struct {
+	State string `json:"state"`
+	Phase string `json:"phase"`
+	Clock int    `json:"clock"`
+}
+
+func (m *TickMessage) Action() string { return "tick" }
+
+type SendSessionMessage struct {
+	Token string `json:"token"`
+}
+
+func (m *SendSessionMessage) Action() string { return "sendSession" }
+
+type SendGameSnapshotMessage struct {
+	Type             string   `json:"type"`
+	State            string   `json:"state"`
+	Phase            string   `json:"phase"`
+	Clock            int      `json:"clock"`
+	Tweet            string   `json:"tweet,omitempty"`
+	Graphemes        []string `json:"graphemes,omitempty"`
+	AuthorChoices    []string `json:"authorChoices,omitempty"`
+	PlayerState      string   `json:"playerState"`
+	Points           float64  `json:"points"`
+	CorrectAnswers   int      `json:"correctAnswers"`
+	IncorrectAnswers int      `json:"incorrectAnswers"`
+	CurrentLetterIdx int      `json:"currentLetterIdx"`
+	TypingMode       string   `json:"typingMode"`
+	ScoringRule      string   `json:"scoringRule"`
+	Settings         GameSettings `json:"settings"`
+	Round            int      `json:"round"`
+	Locked           bool     `json:"locked"`
+	Buffer           []string `json:"buffer,omitempty"`
+	Spectating       bool     `json:"spectating"`
+}
+
+func (m *SendGameSnapshotMessage) Action() string { return "sendGameSnapshot" }
+
+type PongMessage struct{}
+
+func (m *PongMessage) Action() string { return "pong" }
+
+func init() {
+	registerServerMessage(SendGameTypeMessage(""))
+	registerServerMessage(&SendSettingsMessage{})
+	registerServerMessage(SendActivePlayersMessage{})
+	registerServerMessage(SendSpectatorsMessage{})
+	registerServerMessage(PlayerUpdatesMessage{})
+	registerServerMessage(&StartCountdownMessage{})
+	registerServerMessage(&StartGameMessage{})
+	registerServerMessage(&StartFinishMessage{})
+	registerServerMessage(&RoundFinishMessage{})
+	registerServerMessage(&RematchMessage{})
+	registerServerMessage(&RoomLockedMessage{})
+	registerServerMessage(&ReadyCheckMessage{})
+	registerServerMessage(&TickMessage{})
+	registerServerMessage(&SendSessionMessage{})
+	registerServerMessage(&SendGameSnapshotMessage{})
+	registerServerMessage(&PongMessage{})
+}
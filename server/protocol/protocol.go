@@ -0,0 +1,87 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the current protocol version. Clients that don't send a version
+// are treated as speaking version 1.
+const Version = 1
+
+// Envelope is the shape of every websocket message in both directions
+type Envelope struct {
+	Version int             `json:"version"`
+	Action  string          `json:"action"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// ClientMessage is a message sent by a player to the server
+type ClientMessage interface {
+	Action() string
+}
+
+// ServerMessage is a message sent by the server to a player
+type ServerMessage interface {
+	Action() string
+}
+
+// clientMessages maps each client action to a constructor for its message
+var clientMessages = map[string]func() ClientMessage{}
+
+// serverMessages lists every server message, used to build the schema
+var serverMessages = []ServerMessage{}
+
+func registerClientMessage(new_message func() ClientMessage) {
+	clientMessages[new_message().Action()] = new_message
+}
+
+func registerServerMessage(message ServerMessage) {
+	serverMessages = append(serverMessages, message)
+}
+
+// Decode parses a raw websocket message into its typed client message
+func Decode(raw []byte) (ClientMessage, *Error) {
+	var envelope Envelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, NewError(BadMessage, "message is not valid json")
+	}
+
+	if envelope.Version > Version {
+		return nil, NewError(UnsupportedVersion, fmt.Sprintf("protocol version %d is not supported", envelope.Version))
+	}
+
+	new_message, ok := clientMessages[envelope.Action]
+	if !ok {
+		return nil, NewError(UnknownAction, fmt.Sprintf("unknown action %q", envelope.Action))
+	}
+
+	message := new_message()
+	if len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+		if err := json.Unmarshal(envelope.Data, message); err != nil {
+			return nil, NewError(InvalidData, fmt.Sprintf("invalid data for %s", envelope.Action))
+		}
+	}
+
+	if validator, ok := message.(interface{ Validate() *Error }); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return message, nil
+}
+
+// Encode wraps a server message in a versioned envelope
+func Encode(message ServerMessage) ([]byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Envelope{
+		Version: Version,
+		Action: message.Action(),
+		Data: data,
+	})
+}
@@ -0,0 +1,184 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"server/text"
+	"strings"
+	"testing"
+)
+
+func envelope(version int, action string, data string) []byte {
+	raw, _ := json.Marshal(Envelope{Version: version, Action: action, Data: json.RawMessage(data)})
+	return raw
+}
+
+func TestDecodeErrors(t *testing.T) {
+	keys := make([]string, MaxKeysPerBatch + 1)
+	for i := range keys {
+		keys[i] = `{"key":"a","offset":1}`
+	}
+
+	tests := []struct {
+		name string
+		raw  []byte
+		code ErrorCode
+	}{
+		{"not json", []byte("{"), BadMessage},
+		{"newer version", envelope(Version + 1, "ping", ""), UnsupportedVersion},
+		{"unknown action", envelope(Version, "fly", ""), UnknownAction},
+		{"no action", envelope(Version, "", ""), UnknownAction},
+		{"data of the wrong type", envelope(Version, "registerPlayer", `{"name":3}`), InvalidData},
+		{"data that isn't an object", envelope(Version, "playerMove", `"a"`), InvalidData},
+
+		{"no key", envelope(Version, "playerMove", `{}`), InvalidData},
+		{"pasted chunk", envelope(Version, "playerMove", `{"key":"hello"}`), InvalidData},
+		{"made up key name", envelope(Version, "playerMove", `{"key":"Teleport"}`), InvalidData},
+		{"no keys", envelope(Version, "playerKeys", `{"keys":[]}`), InvalidData},
+		{"too many keys", envelope(Version, "playerKeys", `{"keys":[` + strings.Join(keys, ",") + `]}`), InvalidData},
+		{"empty key in a batch", envelope(Version, "playerKeys", `{"keys":[{"key":"","offset":1}]}`), InvalidData},
+		{"chunk in a batch", envelope(Version, "playerKeys", `{"keys":[{"key":"ab","offset":1}]}`), InvalidData},
+		{"negative offset", envelope(Version, "playerKeys", `{"keys":[{"key":"a","offset":-1}]}`), InvalidData},
+		{"offsets out of order", envelope(Version, "playerKeys", `{"keys":[{"key":"a","offset":5},{"key":"b","offset":4}]}`), InvalidData},
+		{"no token", envelope(Version, "resume", `{}`), InvalidData},
+		{"kick nobody", envelope(Version, "kickPlayer", `{}`), InvalidData},
+		{"transfer to nobody", envelope(Version, "transferHost", `{"playerId":""}`), InvalidData},
+	}
+
+	for _, test := range tests {
+		message, err := Decode(test.raw)
+		if err == nil || err.Code != test.code {
+			t.Errorf("%s: got %v %v, want %s", test.name, message, err, test.code)
+		}
+	}
+}
+
+func TestDecodeVersions(t *testing.T) {
+	// Clients that don't say which version they speak get version 1
+	for _, raw := range []string{`{"action":"ping"}`, `{"version":1,"action":"ping"}`} {
+		if message, err := Decode([]byte(raw)); err != nil || message.Action() != "ping" {
+			t.Errorf("%s: got %v %v", raw, message, err)
+		}
+	}
+
+	// Data is optional for messages that don't need any
+	for _, data := range []string{"", "null", "{}"} {
+		if _, err := Decode(envelope(Version, "startCountdown", data)); err != nil {
+			t.Errorf("startCountdown with data %q: %v", data, err)
+		}
+	}
+}
+
+// Every message players can send, as they would send it
+var clientSamples = []ClientMessage{
+	&RegisterPlayerAction{Name: "ada"},
+	&SpectateAction{Name: "grace"},
+	&StartCountdownAction{},
+	&PlayerMoveAction{Key: "é"},
+	&PlayerKeysAction{Keys: []Keystroke{{Key: "a", Offset: 10}, {Key: "Backspace", Offset: 10}, {Key: "👍", Offset: 250}}},
+	&PlayerGuessAction{Guess: "elonmusk"},
+	&PingAction{},
+	&ResumeAction{Token: "abc123"},
+	&UpdateSettingsAction{Settings: GameSettings{MaxPlayers: 4, TimeLimit: 60, Categories: []string{"tech"}, Rounds: 3, Text: &text.DefaultOptions}},
+	&RematchAction{},
+	&KickPlayerAction{PlayerId: "p1"},
+	&TransferHostAction{PlayerId: "p2"},
+	&LockRoomAction{Locked: true},
+	&ReadyAction{Ready: true},
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	seen := map[string]bool{}
+	for _, sample := range clientSamples {
+		seen[sample.Action()] = true
+
+		data, _ := json.Marshal(sample)
+		message, err := Decode(envelope(Version, sample.Action(), string(data)))
+		if err != nil {
+			t.Errorf("%s: %v", sample.Action(), err)
+			continue
+		}
+		if !reflect.DeepEqual(message, sample) {
+			t.Errorf("%s decoded as %+v, want %+v", sample.Action(), message, sample)
+		}
+	}
+
+	for action := range clientMessages {
+		if !seen[action] {
+			t.Errorf("%s has no sample", action)
+		}
+	}
+}
+
+func float(value float64) *float64 { return &value }
+
+// Every message the server sends, filled in
+var serverSamples = []ServerMessage{
+	NewError(NotHost, "Only the host can do that"),
+	SendGameTypeMessage("private"),
+	&SendSettingsMessage{MaxPlayers: 6, Countdown: 5, GuessingEnabled: true, TypingMode: "real", Rounds: 2},
+	SendActivePlayersMessage{{Id: "p1", Name: "ada", Speed: 88.5, IsCreator: true, Connection: "connected", Ghost: "best"}},
+	SendSpectatorsMessage{{Id: "s1", Name: "grace", IsUser: true}},
+	PlayerUpdatesMessage{{Id: "p1", Speed: float(91.25)}},
+	&StartCountdownMessage{State: "countdown", Clock: 5},
+	&StartGameMessage{State: "started", Tweet: "hi there", Graphemes: []string{"h", "i"}, AuthorChoices: []string{"a", "b"}},
+	&StartFinishMessage{
+		State: "finished",
+		Author: "Ada",
+		Round: 2,
+		Rounds: 2,
+		Results: []PlayerResult{{Id: "p1", NetWPM: 80, Samples: []float64{60, 90}, WordErrors: []WordError{{Word: 1, Text: "there", Errors: 2}}, UnderReview: true}},
+		Scoreboard: []MatchStanding{{Id: "p1", Name: "ada", Points: 120, RoundsWon: 2, Placement: 1}},
+	},
+	&RoundFinishMessage{State: "betweenRounds", Round: 1, Rounds: 2, Clock: 10, Results: []PlayerResult{{Id: "p1", Voided: true}}},
+	&RematchMessage{State: "lobby"},
+	&RoomLockedMessage{Locked: true},
+	&ReadyCheckMessage{},
+	&TickMessage{State: "started", Phase: "typing", Clock: 42},
+	&SendSessionMessage{Token: "abc123"},
+	&SendGameSnapshotMessage{Type: "private", State: "started", Tweet: "hi", Graphemes: []string{"h", "i"}, Settings: GameSettings{Rounds: 1}, Buffer: []string{"h"}},
+	&PongMessage{},
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	seen := map[string]bool{}
+	for _, sample := range serverSamples {
+		seen[sample.Action()] = true
+
+		raw, err := Encode(sample)
+		if err != nil {
+			t.Errorf("%s: %v", sample.Action(), err)
+			continue
+		}
+
+		var envelope Envelope
+		if err := json.Unmarshal(raw, &envelope); err != nil || envelope.Version != Version || envelope.Action != sample.Action() {
+			t.Errorf("%s was sent as %s: %v", sample.Action(), raw, err)
+			continue
+		}
+
+		kind := reflect.TypeOf(sample)
+		if kind.Kind() == reflect.Ptr {
+			kind = kind.Elem()
+		}
+		decoded := reflect.New(kind)
+		if err := json.Unmarshal(envelope.Data, decoded.Interface()); err != nil {
+			t.Errorf("%s: %v", sample.Action(), err)
+			continue
+		}
+
+		want := reflect.ValueOf(sample)
+		if want.Kind() != reflect.Ptr {
+			decoded = decoded.Elem()
+		}
+		if !reflect.DeepEqual(decoded.Interface(), want.Interface()) {
+			t.Errorf("%s came back as %+v, want %+v", sample.Action(), decoded.Interface(), sample)
+		}
+	}
+
+	for i := range serverMessages {
+		if !seen[serverMessages[i].Action()] {
+			t.Errorf("%s has no sample", serverMessages[i].Action())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Schema describes every client and server message as JSON Schema, so bots
+// and the web client can be generated from or validated against it
+func Schema() map[string]interface{} {
+	client := map[string]interface{}{}
+	for action, new_message := range clientMessages {
+		client[action] = messageSchema(action, new_message())
+	}
+
+	server := map[string]interface{}{}
+	for i := range serverMessages {
+		server[serverMessages[i].Action()] = messageSchema(serverMessages[i].Action(), serverMessages[i])
+	}
+
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"version": Version,
+		"client": client,
+		"server": server,
+	}
+}
+
+func messageSchema(action string, message interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"version": map[string]interface{}{"type": "integer", "const": Version},
+			"action": map[string]interface{}{"type": "string", "const": action},
+			"data": typeSchema(reflect.TypeOf(message)),
+		},
+		"required": []string{"action"},
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := strings.Split(field.Tag.Get("json"), ",")
+			if !field.IsExported() || tag[0] == "-" {
+				continue
+			}
+
+			name := field.Name
+			if tag[0] != "" {
+				name = tag[0]
+			}
+
+			properties[name] = typeSchema(field.Type)
+			if !strings.Contains(field.Tag.Get("json"), ",omitempty") {
+				required = append(required, name)
+			}
+		}
+
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	}
+
+	return map[string]interface{}{}
+}
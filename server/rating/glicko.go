@@ -0,0 +1,140 @@
+package rating
+
+import "math"
+
+// Competitive ratings use Glicko-2. A game with several players counts as
+// each of them having played every other one: finishing above someone is a
+// win against them and placing the same is a draw.
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+	// How much volatility can change from game to game
+	Tau = 0.5
+	// Deviation never drops below this, so ratings can keep moving
+	MinDeviation = 30.0
+
+	glickoScale = 173.7178
+	convergence = 0.000001
+)
+
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Default is the rating of a player who hasn't played a rated game
+func Default() Rating {
+	return Rating{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// Update works out everyone's new rating after a game. placements[i] is
+// where player i finished, 1 being first.
+func Update(ratings []Rating, placements []int) []Rating {
+	updated := make([]Rating, len(ratings))
+
+	for i := range ratings {
+		var opponents []Rating
+		var scores []float64
+
+		for j := range ratings {
+			if i == j {
+				continue
+			}
+
+			score := 0.5
+			if placements[i] < placements[j] {
+				score = 1
+			} else if placements[i] > placements[j] {
+				score = 0
+			}
+
+			opponents = append(opponents, ratings[j])
+			scores = append(scores, score)
+		}
+
+		updated[i] = rate(ratings[i], opponents, scores)
+	}
+
+	return updated
+}
+
+// rate runs one Glicko-2 rating period for a player
+func rate(player Rating, opponents []Rating, scores []float64) Rating {
+	if len(opponents) == 0 {
+		return player
+	}
+
+	mu := (player.Rating - DefaultRating) / glickoScale
+	phi := player.Deviation / glickoScale
+	sigma := player.Volatility
+
+	// Estimated variance of the rating from the game and the improvement
+	// the results suggest
+	var v_inv, improvement float64
+	for j := range opponents {
+		mu_j := (opponents[j].Rating - DefaultRating) / glickoScale
+		g_j := g(opponents[j].Deviation / glickoScale)
+		e_j := 1 / (1 + math.Exp(-g_j * (mu - mu_j)))
+
+		v_inv += g_j * g_j * e_j * (1 - e_j)
+		improvement += g_j * (scores[j] - e_j)
+	}
+	v := 1 / v_inv
+	delta := v * improvement
+
+	sigma = volatility(phi, sigma, v, delta)
+
+	phi_star := math.Sqrt(phi * phi + sigma * sigma)
+	phi = 1 / math.Sqrt(1 / (phi_star * phi_star) + 1 / v)
+	mu += phi * phi * improvement
+
+	return Rating{
+		Rating: mu * glickoScale + DefaultRating,
+		Deviation: math.Max(phi * glickoScale, MinDeviation),
+		Volatility: sigma,
+	}
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1 + 3 * phi * phi / (math.Pi * math.Pi))
+}
+
+// volatility finds the new volatility with the Illinois algorithm from the
+// Glicko-2 paper
+func volatility(phi float64, sigma float64, v float64, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		e_x := math.Exp(x)
+		d := phi * phi + v + e_x
+		return e_x * (delta * delta - d) / (2 * d * d) - (x - a) / (Tau * Tau)
+	}
+
+	upper := a
+	var lower float64
+	if delta * delta > phi * phi + v {
+		lower = math.Log(delta * delta - phi * phi - v)
+	} else {
+		k := 1.0
+		for f(a - k * Tau) < 0 {
+			k += 1
+		}
+		lower = a - k * Tau
+	}
+
+	f_upper, f_lower := f(upper), f(lower)
+	for math.Abs(lower - upper) > convergence {
+		c := upper + (upper - lower) * f_upper / (f_lower - f_upper)
+		f_c := f(c)
+
+		if f_c * f_lower <= 0 {
+			upper, f_upper = lower, f_lower
+		} else {
+			f_upper /= 2
+		}
+		lower, f_lower = c, f_c
+	}
+
+	return math.Exp(upper / 2)
+}
@@ -0,0 +1,47 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a float64, b float64, within float64) bool {
+	return math.Abs(a - b) <= within
+}
+
+// The example from Glickman's Glicko-2 paper. The player places between the
+// opponent they beat and the two who beat them.
+func TestUpdateGlickman(t *testing.T) {
+	ratings := []Rating{
+		{1500, 200, 0.06},
+		{1400, 30, 0.06},
+		{1550, 100, 0.06},
+		{1700, 300, 0.06},
+	}
+
+	updated := Update(ratings, []int{2, 3, 1, 1})[0]
+	if !near(updated.Rating, 1464.06, 0.01) || !near(updated.Deviation, 151.52, 0.01) || !near(updated.Volatility, 0.05999, 0.00001) {
+		t.Errorf("got %+v, want 1464.06/151.52/0.05999", updated)
+	}
+}
+
+func TestUpdatePlacements(t *testing.T) {
+	ratings := []Rating{Default(), Default(), Default(), Default()}
+
+	updated := Update(ratings, []int{3, 1, 4, 2})
+	order := []int{1, 3, 0, 2}
+	for i := 1; i < len(order); i++ {
+		if updated[order[i]].Rating >= updated[order[i - 1]].Rating {
+			t.Errorf("placing %d rated %.2f, above %.2f", i + 1, updated[order[i]].Rating, updated[order[i - 1]].Rating)
+		}
+	}
+	if updated[1].Rating <= DefaultRating || updated[2].Rating >= DefaultRating {
+		t.Errorf("first went to %.2f and last to %.2f", updated[1].Rating, updated[2].Rating)
+	}
+
+	// Equal players who tie stay where they were
+	tied := Update(ratings[:2], []int{1, 1})
+	if !near(tied[0].Rating, DefaultRating, 0.000001) || tied[0] != tied[1] {
+		t.Errorf("a tie rated %+v", tied)
+	}
+}
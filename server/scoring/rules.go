@@ -0,0 +1,153 @@
+package scoring
+
+import (
+	"sort"
+	"time"
+)
+
+// Points for guessing the author of the tweet
+const GuessBonus = 10
+
+// Standing is what a rule knows about one player when the game ends
+type Standing struct {
+	Result        Result
+	GuessedAuthor bool
+	// Graphemes of the tweet the player got through
+	Progress int
+	// Whether the player typed the whole tweet and how long it took them
+	Finished   bool
+	TypingTime time.Duration
+}
+
+// ScoringRule decides how many points each player gets at the end of a
+// game. Players are placed by points, highest first.
+type ScoringRule interface {
+	Name() string
+	Points(standings []Standing) []float64
+}
+
+var rules = make(map[string]ScoringRule)
+
+func registerRule(rule ScoringRule) {
+	rules[rule.Name()] = rule
+}
+
+// Rule returns the built in rule with the given name
+func Rule(name string) (ScoringRule, bool) {
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+// RuleNames lists the built in rules
+func RuleNames() []string {
+	names := []string{}
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+const DefaultRule = "classic"
+
+// Classic is net WPM plus a bonus for guessing the author
+type Classic struct{}
+
+func (r Classic) Name() string { return "classic" }
+
+func (r Classic) Points(standings []Standing) []float64 {
+	points := make([]float64, len(standings))
+	for i := range standings {
+		points[i] = standings[i].Result.NetWPM + guessPoints(standings[i])
+	}
+	return points
+}
+
+// SpeedOnly ignores the guess
+type SpeedOnly struct{}
+
+func (r SpeedOnly) Name() string { return "speed" }
+
+func (r SpeedOnly) Points(standings []Standing) []float64 {
+	points := make([]float64, len(standings))
+	for i := range standings {
+		points[i] = standings[i].Result.NetWPM
+	}
+	return points
+}
+
+// GuessOnly only rewards guessing the author; typing just gets you there
+type GuessOnly struct{}
+
+func (r GuessOnly) Name() string { return "guess" }
+
+func (r GuessOnly) Points(standings []Standing) []float64 {
+	points := make([]float64, len(standings))
+	for i := range standings {
+		points[i] = guessPoints(standings[i])
+	}
+	return points
+}
+
+// SpeedAccuracy scales net WPM by accuracy so mashing keys doesn't pay
+type SpeedAccuracy struct{}
+
+func (r SpeedAccuracy) Name() string { return "speedAccuracy" }
+
+func (r SpeedAccuracy) Points(standings []Standing) []float64 {
+	points := make([]float64, len(standings))
+	for i := range standings {
+		points[i] = standings[i].Result.NetWPM * standings[i].Result.Accuracy
+	}
+	return points
+}
+
+// Placement gives fixed points by typing order. Players who finished rank by
+// time, the rest by how far they got.
+type Placement struct{}
+
+var PlacementPoints = []float64{10, 7, 5, 3, 2, 1}
+
+func (r Placement) Name() string { return "placement" }
+
+func (r Placement) Points(standings []Standing) []float64 {
+	order := make([]int, len(standings))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := standings[order[i]], standings[order[j]]
+		if a.Finished != b.Finished {
+			return a.Finished
+		}
+		if a.Finished {
+			return a.TypingTime < b.TypingTime
+		}
+		return a.Progress > b.Progress
+	})
+
+	points := make([]float64, len(standings))
+	for place, i := range order {
+		// Players who didn't type anything don't place
+		if place < len(PlacementPoints) && standings[i].Progress > 0 {
+			points[i] = PlacementPoints[place]
+		}
+	}
+	return points
+}
+
+func guessPoints(standing Standing) float64 {
+	if standing.GuessedAuthor {
+		return GuessBonus
+	}
+	return 0
+}
+
+func init() {
+	registerRule(Classic{})
+	registerRule(SpeedOnly{})
+	registerRule(GuessOnly{})
+	registerRule(SpeedAccuracy{})
+	registerRule(Placement{})
+}
@@ -0,0 +1,129 @@
+package scoring
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// placements orders players by points, highest first, keeping the order
+// they came in for ties the way the game does
+func placements(points []float64) []int {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return points[order[i]] > points[order[j]] })
+	return order
+}
+
+func finished(wpm float64, accuracy float64, guessed bool, seconds int) Standing {
+	return Standing{
+		Result: Result{NetWPM: wpm, Accuracy: accuracy},
+		GuessedAuthor: guessed,
+		Progress: 100,
+		Finished: true,
+		TypingTime: time.Duration(seconds) * time.Second,
+	}
+}
+
+func unfinished(wpm float64, accuracy float64, guessed bool, progress int) Standing {
+	return Standing{Result: Result{NetWPM: wpm, Accuracy: accuracy}, GuessedAuthor: guessed, Progress: progress}
+}
+
+func TestRules(t *testing.T) {
+	// Fast but wrong guess, slower with the right guess, didn't finish and
+	// didn't type anything
+	game := []Standing{
+		finished(80, 0.9, false, 30),
+		finished(70, 1, true, 35),
+		unfinished(40, 0.8, true, 60),
+		unfinished(0, 0, false, 0),
+	}
+
+	tests := []struct {
+		name      string
+		rule      ScoringRule
+		standings []Standing
+		points    []float64
+		order     []int
+	}{
+		// The guess makes up the speed difference, so the first two tie
+		{"classic", Classic{}, game, []float64{80, 80, 50, 0}, []int{0, 1, 2, 3}},
+		{"speed", SpeedOnly{}, game, []float64{80, 70, 40, 0}, []int{0, 1, 2, 3}},
+		{"guess", GuessOnly{}, game, []float64{0, 10, 10, 0}, []int{1, 2, 0, 3}},
+		{"speed accuracy", SpeedAccuracy{}, game, []float64{72, 70, 32, 0}, []int{0, 1, 2, 3}},
+		{"placement", Placement{}, game, []float64{10, 7, 5, 0}, []int{0, 1, 2, 3}},
+
+		{"classic unfinished ahead", Classic{}, []Standing{
+			unfinished(95, 0.9, false, 90),
+			finished(60, 1, true, 50),
+		}, []float64{95, 70}, []int{0, 1}},
+
+		// Finishing beats getting further, and faster beats slower
+		{"placement finish order", Placement{}, []Standing{
+			unfinished(95, 0.9, true, 90),
+			finished(50, 1, false, 60),
+			finished(60, 1, false, 40),
+			unfinished(30, 1, false, 95),
+		}, []float64{3, 7, 10, 5}, []int{2, 1, 3, 0}},
+
+		{"placement ties", Placement{}, []Standing{
+			finished(60, 1, false, 40),
+			unfinished(30, 1, false, 20),
+			finished(60, 1, false, 40),
+			unfinished(30, 1, false, 20),
+		}, []float64{10, 5, 7, 3}, []int{0, 2, 1, 3}},
+
+		// Only six places score, and nobody scores for typing nothing
+		{"placement beyond the points", Placement{}, []Standing{
+			finished(60, 1, false, 10),
+			finished(60, 1, false, 20),
+			finished(60, 1, false, 30),
+			finished(60, 1, false, 40),
+			finished(60, 1, false, 50),
+			unfinished(0, 0, false, 0),
+			finished(60, 1, false, 60),
+			finished(60, 1, false, 70),
+		}, []float64{10, 7, 5, 3, 2, 0, 1, 0}, []int{0, 1, 2, 3, 4, 6, 5, 7}},
+
+		{"nobody typed", Placement{}, []Standing{
+			unfinished(0, 0, true, 0),
+			unfinished(0, 0, false, 0),
+		}, []float64{0, 0}, []int{0, 1}},
+		{"nobody typed classic", Classic{}, []Standing{
+			unfinished(0, 0, false, 0),
+			unfinished(0, 0, true, 0),
+		}, []float64{0, 10}, []int{1, 0}},
+
+		{"no players", SpeedAccuracy{}, nil, []float64{}, []int{}},
+	}
+
+	for _, test := range tests {
+		points := test.rule.Points(test.standings)
+		if !reflect.DeepEqual(points, test.points) {
+			t.Errorf("%s: points %v, want %v", test.name, points, test.points)
+			continue
+		}
+		if order := placements(points); !reflect.DeepEqual(order, test.order) {
+			t.Errorf("%s: placed %v, want %v", test.name, order, test.order)
+		}
+	}
+}
+
+func TestRuleNames(t *testing.T) {
+	want := []string{"classic", "guess", "placement", "speed", "speedAccuracy"}
+	if names := RuleNames(); !reflect.DeepEqual(names, want) {
+		t.Errorf("RuleNames() = %v, want %v", names, want)
+	}
+
+	for _, name := range want {
+		if rule, ok := Rule(name); !ok || rule.Name() != name {
+			t.Errorf("Rule(%q) = %v, %v", name, rule, ok)
+		}
+	}
+	if _, ok := Rule(DefaultRule); !ok {
+		t.Errorf("the default rule %q isn't registered", DefaultRule)
+	}
+}
@@ -0,0 +1,113 @@
+package scoring
+
+import (
+	"math"
+	"time"
+)
+
+// Speeds follow the usual typing test definitions where a word is five
+// characters. Characters here are graphemes.
+const CharactersPerWord = 5
+
+type Input struct {
+	// Graphemes left in the typed text, right or wrong
+	Entries int
+	// Keystrokes that typed the right grapheme and the wrong one
+	Correct   int
+	Incorrect int
+	// Wrong graphemes still in the text when the player stopped typing
+	UncorrectedErrors int
+	// How long the player typed for
+	Duration time.Duration
+	// When each grapheme of progress was typed, from the start of the round
+	Progress []time.Duration
+}
+
+type Result struct {
+	GrossWPM    float64   `json:"grossWpm"`
+	NetWPM      float64   `json:"netWpm"`
+	Accuracy    float64   `json:"accuracy"`
+	Samples     []float64 `json:"samples"`
+	Consistency float64   `json:"consistency"`
+}
+
+// Score works out a player's speed and accuracy. Players who didn't type
+// anything score zero across the board.
+func Score(input Input) Result {
+	result := Result{Samples: Samples(input.Progress, input.Duration)}
+
+	minutes := input.Duration.Minutes()
+	if minutes > 0 {
+		result.GrossWPM = float64(input.Entries) / CharactersPerWord / minutes
+		result.NetWPM = math.Max(0, result.GrossWPM - float64(input.UncorrectedErrors) / minutes)
+	}
+
+	if keys := input.Correct + input.Incorrect; keys > 0 {
+		result.Accuracy = float64(input.Correct) / float64(keys)
+	}
+
+	result.Consistency = Consistency(result.Samples)
+	return result
+}
+
+// Samples returns the speed the player typed at during each second. A last
+// second shorter than half a second is folded into the one before it so a
+// single late key doesn't show up as a spike.
+func Samples(progress []time.Duration, duration time.Duration) []float64 {
+	if duration <= 0 {
+		return []float64{}
+	}
+
+	seconds := int(duration / time.Second)
+	remainder := duration % time.Second
+	if remainder >= time.Second / 2 || seconds == 0 {
+		seconds += 1
+	} else {
+		remainder += time.Second
+	}
+
+	counts := make([]int, seconds)
+	for i := range progress {
+		bucket := int(progress[i] / time.Second)
+		if bucket >= seconds { bucket = seconds - 1 }
+		if bucket < 0 { bucket = 0 }
+		counts[bucket] += 1
+	}
+
+	samples := make([]float64, seconds)
+	for i := range counts {
+		length := time.Second
+		if i == seconds - 1 && remainder > 0 {
+			length = remainder
+		}
+		samples[i] = float64(counts[i]) / CharactersPerWord / length.Minutes()
+	}
+
+	return samples
+}
+
+// Consistency is 100 minus the coefficient of variation of the samples as a
+// percentage, so steady typing scores close to 100
+func Consistency(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var mean float64 = 0
+	for i := range samples {
+		mean += samples[i]
+	}
+	mean /= float64(len(samples))
+
+	if mean == 0 {
+		return 0
+	}
+
+	var variance float64 = 0
+	for i := range samples {
+		variance += (samples[i] - mean) * (samples[i] - mean)
+	}
+	variance /= float64(len(samples))
+
+	return math.Max(0, 100 - math.Sqrt(variance) / mean * 100)
+}
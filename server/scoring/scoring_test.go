@@ -0,0 +1,127 @@
+package scoring
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func near(a float64, b float64) bool {
+	return math.Abs(a - b) < 0.01
+}
+
+// steady types a grapheme every interval, half an interval off the beat,
+// until duration
+func steady(interval time.Duration, duration time.Duration) []time.Duration {
+	var progress []time.Duration
+	for at := interval / 2; at < duration; at += interval {
+		progress = append(progress, at)
+	}
+	return progress
+}
+
+func TestScore(t *testing.T) {
+	minute := time.Minute
+
+	tests := []struct {
+		name     string
+		input    Input
+		gross    float64
+		net      float64
+		accuracy float64
+	}{
+		{"clean minute", Input{Entries: 250, Correct: 250, Duration: minute}, 50, 50, 1},
+		{"half a minute", Input{Entries: 125, Correct: 125, Duration: minute / 2}, 50, 50, 1},
+		// Mistakes that were fixed cost accuracy but not speed
+		{"corrected errors", Input{Entries: 250, Correct: 250, Incorrect: 10, Duration: minute}, 50, 50, 250.0 / 260},
+		// Mistakes left in cost a word a minute each
+		{"uncorrected errors", Input{Entries: 250, Correct: 245, Incorrect: 5, UncorrectedErrors: 5, Duration: minute}, 50, 45, 0.98},
+		{"more errors than words", Input{Entries: 5, Incorrect: 5, UncorrectedErrors: 5, Duration: minute}, 1, 0, 0},
+		{"idle", Input{Duration: minute}, 0, 0, 0},
+		{"never started", Input{}, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		result := Score(test.input)
+		if !near(result.GrossWPM, test.gross) || !near(result.NetWPM, test.net) || !near(result.Accuracy, test.accuracy) {
+			t.Errorf("%s: got %.2f gross %.2f net %.3f accuracy, want %.2f %.2f %.3f",
+				test.name, result.GrossWPM, result.NetWPM, result.Accuracy, test.gross, test.net, test.accuracy)
+		}
+
+		for _, value := range append([]float64{result.GrossWPM, result.NetWPM, result.Accuracy, result.Consistency}, result.Samples...) {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				t.Errorf("%s: %+v isn't a number", test.name, result)
+				break
+			}
+		}
+	}
+}
+
+func TestIdlePlayer(t *testing.T) {
+	result := Score(Input{Duration: 3 * time.Second})
+	if !reflect.DeepEqual(result.Samples, []float64{0, 0, 0}) || result.Consistency != 0 {
+		t.Errorf("an idle player scored %+v", result)
+	}
+
+	result = Score(Input{})
+	if result.Samples == nil || len(result.Samples) != 0 || result.Consistency != 0 {
+		t.Errorf("a player who never started scored %+v", result)
+	}
+}
+
+func TestSamples(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name     string
+		progress []time.Duration
+		duration time.Duration
+		samples  []float64
+	}{
+		// Five graphemes a second is 60 WPM
+		{"steady", steady(200 * ms, 3 * time.Second), 3 * time.Second, []float64{60, 60, 60}},
+		{"under a second", []time.Duration{100 * ms, 200 * ms}, 500 * ms, []float64{48}},
+		// A short last second is folded into the one before it
+		{"short last second", []time.Duration{100 * ms, 1100 * ms, 2200 * ms}, 2300 * ms, []float64{12, 24 / 1.3}},
+		{"long last second", []time.Duration{100 * ms, 1100 * ms, 2200 * ms}, 2600 * ms, []float64{12, 12, 20}},
+		{"a key after the end", []time.Duration{2500 * ms}, 2 * time.Second, []float64{0, 12}},
+		{"nothing typed", nil, 2 * time.Second, []float64{0, 0}},
+		{"no time", []time.Duration{100 * ms}, 0, []float64{}},
+	}
+
+	for _, test := range tests {
+		samples := Samples(test.progress, test.duration)
+		if len(samples) != len(test.samples) {
+			t.Errorf("%s: got %v, want %v", test.name, samples, test.samples)
+			continue
+		}
+		for i := range samples {
+			if !near(samples[i], test.samples[i]) {
+				t.Errorf("%s: got %v, want %v", test.name, samples, test.samples)
+				break
+			}
+		}
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	tests := []struct {
+		samples     []float64
+		consistency float64
+	}{
+		{[]float64{60, 60, 60}, 100},
+		{[]float64{60, 120}, 100 - 30.0 / 90 * 100},
+		{[]float64{60, 0}, 0},
+		// Far too uneven bottoms out at zero
+		{[]float64{300, 0, 0, 0}, 0},
+		{[]float64{0, 0}, 0},
+		{nil, 0},
+	}
+
+	for _, test := range tests {
+		if consistency := Consistency(test.samples); !near(consistency, test.consistency) {
+			t.Errorf("%v: got %.2f, want %.2f", test.samples, consistency, test.consistency)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package text
+
+import "unicode"
+
+const zeroWidthJoiner = 0x200D
+
+// Graphemes splits s into user-perceived characters. It follows the parts of
+// the Unicode extended grapheme cluster rules that matter for tweets:
+// combining marks, emoji modifiers and ZWJ sequences, and flag pairs.
+func Graphemes(s string) []string {
+	runes := []rune(s)
+	clusters := []string{}
+	start := 0
+	regional_count := 0
+
+	for i := range runes {
+		if i == start {
+			if isRegionalIndicator(runes[i]) { regional_count = 1 } else { regional_count = 0 }
+			continue
+		}
+
+		prev := runes[i - 1]
+		r := runes[i]
+
+		join := false
+		switch {
+		case prev == '\r' && r == '\n':
+			join = true
+		case isExtend(r):
+			join = true
+		case prev == zeroWidthJoiner && isPictographic(r):
+			join = true
+		case isRegionalIndicator(r) && regional_count % 2 == 1:
+			join = true
+		}
+
+		if !join {
+			clusters = append(clusters, string(runes[start:i]))
+			start = i
+			regional_count = 0
+		}
+
+		if isRegionalIndicator(r) { regional_count += 1 }
+	}
+
+	if start < len(runes) {
+		clusters = append(clusters, string(runes[start:]))
+	}
+
+	return clusters
+}
+
+// isExtend reports whether r attaches to the character before it
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zeroWidthJoiner ||
+		(r >= 0xFE00 && r <= 0xFE0F) ||   // variation selectors
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // skin tone modifiers
+		(r >= 0xE0020 && r <= 0xE007F) || // tags
+		(r >= 0xE0100 && r <= 0xE01EF)    // variation selectors supplement
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isPictographic approximates the Extended_Pictographic property
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0x2190 && r <= 0x21FF, r >= 0x2300 && r <= 0x23FF:
+		return true
+	case r >= 0x2600 && r <= 0x27BF, r >= 0x2900 && r <= 0x297F:
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF:
+		return true
+	}
+
+	switch r {
+	case 0x00A9, 0x00AE, 0x203C, 0x2049, 0x2122, 0x2139, 0x3030, 0x303D, 0x3297, 0x3299:
+		return true
+	}
+	return false
+}
+
+// IsEmoji reports whether a grapheme is an emoji, including flags and
+// keycaps like 1️⃣
+func IsEmoji(grapheme string) bool {
+	for i, r := range grapheme {
+		if i == 0 && (isPictographic(r) || isRegionalIndicator(r)) {
+			return true
+		}
+		if r == 0x20E3 || r == 0xFE0F {
+			return true
+		}
+	}
+	return false
+}
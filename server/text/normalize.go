@@ -0,0 +1,163 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Options controls how tweets and keys are normalized before they are
+// compared
+type Options struct {
+	// Replace curly quotes, dashes and ellipses with what's on a keyboard
+	SmartQuotes bool `json:"smartQuotes"`
+	// Let e match é, o match ø and so on
+	FoldAccents bool `json:"foldAccents"`
+	// Leave emoji out of the text that has to be typed
+	SkipEmoji bool `json:"skipEmoji"`
+}
+
+var DefaultOptions = Options{
+	SmartQuotes: true,
+	FoldAccents: false,
+	SkipEmoji: true,
+}
+
+var punctuation = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'",
+	'“': "\"", '”': "\"", '„': "\"", '‟': "\"", '″': "\"",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+	'…': "...",
+}
+
+// Key values browsers send for keys that don't type anything
+var namedKeys = map[string]bool{
+	"Shift": true, "Control": true, "Alt": true, "AltGraph": true, "Meta": true,
+	"OS": true, "Super": true, "Hyper": true, "Fn": true, "FnLock": true,
+	"CapsLock": true, "NumLock": true, "ScrollLock": true, "Symbol": true, "SymbolLock": true,
+	"Tab": true, "Enter": true, "Escape": true, "Backspace": true, "Delete": true,
+	"Insert": true, "Clear": true, "Home": true, "End": true, "PageUp": true, "PageDown": true,
+	"ArrowUp": true, "ArrowDown": true, "ArrowLeft": true, "ArrowRight": true,
+	"ContextMenu": true, "Pause": true, "PrintScreen": true, "Help": true,
+	"Dead": true, "Process": true, "Compose": true, "Unidentified": true,
+	"Convert": true, "NonConvert": true, "KanaMode": true, "HiraganaKatakana": true,
+	"Hankaku": true, "Zenkaku": true,
+	"AudioVolumeUp": true, "AudioVolumeDown": true, "AudioVolumeMute": true,
+	"MediaPlayPause": true, "MediaTrackNext": true, "MediaTrackPrevious": true, "MediaStop": true,
+}
+
+func init() {
+	for i := 1; i <= 24; i++ {
+		namedKeys[fmt.Sprintf("F%d", i)] = true
+	}
+}
+
+// letters that have no canonical decomposition but still fold to ASCII
+var foldedLetters = map[rune]string{
+	'ø': "o", 'Ø': "O", 'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+	'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ß': "ss", 'þ': "th", 'Þ': "TH", 'ı': "i",
+}
+
+// Prepare turns a tweet into the graphemes a player has to type. Whitespace
+// is collapsed to single spaces since players can't type newlines.
+func Prepare(tweet string, options Options) []string {
+	var graphemes []string
+
+	for _, grapheme := range Graphemes(options.replacePunctuation(tweet)) {
+		if options.SkipEmoji && IsEmoji(grapheme) {
+			continue
+		}
+
+		if strings.TrimSpace(grapheme) == "" {
+			// Drop leading and repeated spaces, including ones left by emoji
+			if len(graphemes) == 0 || graphemes[len(graphemes) - 1] == " " {
+				continue
+			}
+			grapheme = " "
+		}
+
+		// Letters like æ fold to more than one key, so they are typed that way
+		if folded := Graphemes(canonical(grapheme, options)); options.FoldAccents && len(folded) > 1 {
+			graphemes = append(graphemes, folded...)
+			continue
+		}
+
+		graphemes = append(graphemes, grapheme)
+	}
+
+	if len(graphemes) > 0 && graphemes[len(graphemes) - 1] == " " {
+		graphemes = graphemes[:len(graphemes) - 1]
+	}
+
+	return graphemes
+}
+
+// ValidKey reports whether a keystroke is one key: a single grapheme or a
+// named key. Anything longer, like pasted text, isn't.
+func ValidKey(key string) bool {
+	return namedKeys[key] || len(Graphemes(key)) == 1
+}
+
+// Keys returns the graphemes a key stands for. Named keys like "Shift" or
+// "Enter" produce nothing, and so does anything that isn't a valid key.
+func Keys(key string, options Options) []string {
+	if namedKeys[key] || !ValidKey(key) {
+		return nil
+	}
+	return Graphemes(options.replacePunctuation(key))
+}
+
+// Match reports whether a typed grapheme matches the expected one
+func Match(expected string, typed string, options Options) bool {
+	if expected == typed {
+		return true
+	}
+	return canonical(expected, options) == canonical(typed, options)
+}
+
+func (o Options) replacePunctuation(s string) string {
+	if !o.SmartQuotes {
+		return s
+	}
+
+	var builder strings.Builder
+	for _, r := range s {
+		if replacement, ok := punctuation[r]; ok {
+			builder.WriteString(replacement)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// canonical decomposes precomposed letters so é and e + ◌́ compare equal,
+// and drops the accents altogether when folding
+func canonical(grapheme string, options Options) string {
+	var builder strings.Builder
+
+	for _, r := range grapheme {
+		if unicode.IsSpace(r) {
+			builder.WriteRune(' ')
+			continue
+		}
+
+		decomposed, ok := decompositions[r]
+		if !ok {
+			decomposed = string(r)
+		}
+
+		for _, d := range decomposed {
+			if options.FoldAccents && unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			if folded, ok := foldedLetters[d]; ok && options.FoldAccents {
+				builder.WriteString(folded)
+				continue
+			}
+			builder.WriteRune(d)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,227 @@
+package text
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var allOptions = []Options{
+	DefaultOptions,
+	{},
+	{SmartQuotes: true, FoldAccents: true, SkipEmoji: true},
+	{FoldAccents: true},
+}
+
+// readCorpus returns the tweets and author names the server plays with
+func readCorpus(t *testing.T) ([]string, []string) {
+	var tweets []struct {
+		Content string `json:"tweet_text"`
+	}
+	var users []struct {
+		Name string `json:"name"`
+	}
+
+	for path, into := range map[string]interface{}{"../tweets.json": &tweets, "../users.json": &users} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, into); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var contents, names []string
+	for i := range tweets {
+		contents = append(contents, tweets[i].Content)
+	}
+	for i := range users {
+		names = append(names, users[i].Name)
+	}
+	return contents, names
+}
+
+// corpusEntry returns the first tweet or author name containing s
+func corpusEntry(t *testing.T, corpus []string, s string) string {
+	for i := range corpus {
+		if strings.Contains(corpus[i], s) {
+			return corpus[i]
+		}
+	}
+	t.Fatalf("nothing in the corpus contains %q", s)
+	return ""
+}
+
+func TestPrepare(t *testing.T) {
+	tweets, names := readCorpus(t)
+	curly := corpusEntry(t, tweets, "There’s the individual")
+	beyonce := corpusEntry(t, names, "BEYONC")
+	kevin := corpusEntry(t, names, "KΞVIN")
+
+	tests := []struct {
+		name    string
+		tweet   string
+		options Options
+		want    []string
+	}{
+		{"curly quote", curly[:len("There’s")], DefaultOptions, []string{"T", "h", "e", "r", "e", "'", "s"}},
+		{"curly quote kept", curly[:len("There’s")], Options{}, []string{"T", "h", "e", "r", "e", "’", "s"}},
+		{"accented name", beyonce, DefaultOptions, []string{"B", "E", "Y", "O", "N", "C", "É"}},
+		{"decomposed accent", "CE\u0301", DefaultOptions, []string{"C", "E\u0301"}},
+		{"em dash", "wait — what", DefaultOptions, []string{"w", "a", "i", "t", " ", "-", " ", "w", "h", "a", "t"}},
+		{"ellipsis", "so…", DefaultOptions, []string{"s", "o", ".", ".", "."}},
+		{"emoji in a name", kevin, DefaultOptions, []string{"K", "Ξ", "V", "I", "N", " ", "R", "◎", "S", "E", " ", "(", ",", ")"}},
+		{"emoji in a name kept", kevin[len(kevin) - len("(🪹,🦉)"):], Options{}, []string{"(", "🪹", ",", "🦉", ")"}},
+		{"emoji skipped", "hi 😍🔥 all", DefaultOptions, []string{"h", "i", " ", "a", "l", "l"}},
+		{"emoji kept", "hi 😍🔥", Options{}, []string{"h", "i", " ", "😍", "🔥"}},
+		{"joined emoji", "👨‍👩‍👧!", Options{}, []string{"👨‍👩‍👧", "!"}},
+		{"flag", "🇬🇧", Options{}, []string{"🇬🇧"}},
+		{"trailing emoji", "done 🎉", DefaultOptions, []string{"d", "o", "n", "e"}},
+		{"whitespace", "  a\n\n b\t", DefaultOptions, []string{"a", " ", "b"}},
+		{"folded letter", "Straße", Options{FoldAccents: true}, []string{"S", "t", "r", "a", "s", "s", "e"}},
+		{"empty", "", DefaultOptions, nil},
+	}
+
+	for _, test := range tests {
+		if got := Prepare(test.tweet, test.options); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Prepare(%q) = %q, want %q", test.name, test.tweet, got, test.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		key     string
+		options Options
+		want    []string
+	}{
+		{"a", DefaultOptions, []string{"a"}},
+		{"É", DefaultOptions, []string{"É"}},
+		{"’", DefaultOptions, []string{"'"}},
+		{"’", Options{}, []string{"’"}},
+		{"—", DefaultOptions, []string{"-"}},
+		{"…", DefaultOptions, []string{".", ".", "."}},
+		{"🔥", DefaultOptions, []string{"🔥"}},
+		{"e\u0301", DefaultOptions, []string{"e\u0301"}},
+		{"Shift", DefaultOptions, nil},
+		{"Enter", DefaultOptions, nil},
+		{"F1", DefaultOptions, nil},
+		{"Backspace", DefaultOptions, nil},
+		// A keystroke is one key, not a pasted chunk
+		{"hello", DefaultOptions, nil},
+		{"hello!", DefaultOptions, nil},
+		{"a b", DefaultOptions, nil},
+		{"——", DefaultOptions, nil},
+		{"😍🔥", Options{}, nil},
+		{"F99", DefaultOptions, nil},
+	}
+
+	for _, test := range tests {
+		if got := Keys(test.key, test.options); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Keys(%q, %+v) = %q, want %q", test.key, test.options, got, test.want)
+		}
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"a", true},
+		{" ", true},
+		{"É", true},
+		{"e\u0301", true},
+		{"👨‍👩‍👧", true},
+		{"🇬🇧", true},
+		{"…", true},
+		{"Shift", true},
+		{"ArrowLeft", true},
+		{"F12", true},
+		{"F25", false},
+		{"hello", false},
+		{"hello!", false},
+		{"ab", false},
+		{"shift", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := ValidKey(test.key); got != test.want {
+			t.Errorf("ValidKey(%q) = %v, want %v", test.key, got, test.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	fold := Options{FoldAccents: true}
+
+	tests := []struct {
+		expected string
+		typed    string
+		options  Options
+		want     bool
+	}{
+		{"a", "a", DefaultOptions, true},
+		{"a", "b", DefaultOptions, false},
+		{"É", "É", DefaultOptions, true},
+		{"É", "E\u0301", DefaultOptions, true},
+		{"E\u0301", "E", fold, true},
+		{"É", "E", DefaultOptions, false},
+		{"É", "E", fold, true},
+		{"É", "e", fold, false},
+		{"ø", "o", fold, true},
+		{"ø", "o", DefaultOptions, false},
+		{"😍", "😍", Options{}, true},
+		{"😍", "🔥", Options{}, false},
+		{" ", " ", DefaultOptions, true},
+	}
+
+	for _, test := range tests {
+		if got := Match(test.expected, test.typed, test.options); got != test.want {
+			t.Errorf("Match(%q, %q, %+v) = %v, want %v", test.expected, test.typed, test.options, got, test.want)
+		}
+	}
+}
+
+// TestCorpus checks every tweet and author name can be typed by pressing
+// the keys for its own graphemes, and that with the default options the
+// tweets only need keys on a US keyboard
+func TestCorpus(t *testing.T) {
+	tweets, names := readCorpus(t)
+	corpus := append(append([]string{}, tweets...), names...)
+
+	for _, options := range allOptions {
+		for _, entry := range corpus {
+			graphemes := Prepare(entry, options)
+			if len(graphemes) == 0 {
+				t.Errorf("%q has nothing to type", entry)
+				continue
+			}
+			if graphemes[0] == " " || graphemes[len(graphemes) - 1] == " " {
+				t.Errorf("%q starts or ends with a space", entry)
+			}
+
+			for i, grapheme := range graphemes {
+				keys := Keys(grapheme, options)
+				if len(keys) != 1 || !Match(grapheme, keys[0], options) {
+					t.Errorf("%+v: grapheme %d %q of %q is typed as %q", options, i, grapheme, entry, keys)
+					break
+				}
+			}
+		}
+	}
+
+	for _, entry := range tweets {
+		for _, grapheme := range Prepare(entry, DefaultOptions) {
+			for _, r := range grapheme {
+				if r < ' ' || r > '~' {
+					t.Errorf("%q needs %q", entry, grapheme)
+				}
+			}
+		}
+	}
+}
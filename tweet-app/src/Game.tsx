@@ -125,7 +125,17 @@ const Lobby: React.FC<LobbyProps> = ({ gameManager, performAction }) => {
           </AvatarGroup>
         ))}
       </Flex>
-      {user?.isCreator === true ? (
+      {gameManager.spectators.length > 0 && (
+        <Box mt={"2"} color={"gray.500"}>
+          {"Watching: "}
+          {gameManager.spectators.map((s) => s.name).join(", ")}
+        </Box>
+      )}
+      {gameManager.spectating ? (
+        <Box mt={"4"} fontSize={"lg"}>
+          {"You are spectating this game"}
+        </Box>
+      ) : user?.isCreator === true ? (
         <>
           <Button
             mt={"4"}
@@ -143,6 +153,18 @@ const Lobby: React.FC<LobbyProps> = ({ gameManager, performAction }) => {
             {"Start"}
           </Button>
         </>
+      ) : gameManager.readyCheck ? (
+        <Button
+          mt={"4"}
+          width={"24"}
+          variant={user?.ready ? "outline" : "solid"}
+          colorScheme="twitter"
+          onClick={() =>
+            performAction({ action: "ready", data: { ready: !user?.ready } })
+          }
+        >
+          {user?.ready ? "Not ready" : "Ready"}
+        </Button>
       ) : (
         <Box mt={"4"} fontSize={"lg"}>
           {"Waiting for "}
@@ -277,11 +299,11 @@ const Countdown: React.FC<CountdownProps> = ({ timer }) => {
 };
 
 interface AnswerProps {
-  tweet: string;
+  graphemes: string[];
   currentIdx: number;
 }
 
-const Answer: React.FC<AnswerProps> = ({ tweet, currentIdx }) => {
+const Answer: React.FC<AnswerProps> = ({ graphemes, currentIdx }) => {
   return (
     <Flex
       p={"4"}
@@ -293,7 +315,7 @@ const Answer: React.FC<AnswerProps> = ({ tweet, currentIdx }) => {
       borderColor={"gray.300"}
       backgroundColor={"gray.100"}
     >
-      {[...tweet].map((t, i) => (
+      {graphemes.map((t, i) => (
         <Flex key={i} justify={"end"} align={"center"} flexDir={"column"}>
           <Box
             fontSize={"2xl"}
@@ -466,7 +488,7 @@ const Game: React.FC<GameProps> = ({ gameManager, performAction }) => {
       )}
       {gameManager.state === "Started" && (
         <Answer
-          tweet={gameManager.tweet.tweet}
+          graphemes={gameManager.tweet.graphemes}
           currentIdx={user.currentLetterIdx}
         />
       )}
@@ -498,7 +520,7 @@ const Game: React.FC<GameProps> = ({ gameManager, performAction }) => {
               width={"md"}
               colorScheme={"twitter"}
               value={user.currentLetterIdx}
-              max={gameManager.tweet.tweet.length}
+              max={gameManager.tweet.graphemes.length}
             />
           </Flex>
         </Flex>
@@ -527,7 +549,7 @@ const Game: React.FC<GameProps> = ({ gameManager, performAction }) => {
               <Progress
                 width={"md"}
                 value={opp.currentLetterIdx}
-                max={gameManager.tweet.tweet.length}
+                max={gameManager.tweet.graphemes.length}
               />
             </Flex>
           </Flex>
@@ -558,9 +580,10 @@ const ordinal_suffix_of = (i: number) => {
 
 interface FinishedProps {
   gameManager: GameManager;
+  performAction: (action: Action) => void;
 }
 
-const Finished: React.FC<FinishedProps> = ({ gameManager }) => {
+const Finished: React.FC<FinishedProps> = ({ gameManager, performAction }) => {
   const auth = useAuth();
   const toast = useToast();
   const navigate = useNavigate();
@@ -639,9 +662,26 @@ const Finished: React.FC<FinishedProps> = ({ gameManager }) => {
         >
           {gameManager.tweet.tweet}
         </Box>
-        <Button variant={"outline"} onClick={playAgain} colorScheme={"twitter"}>
-          {"Play Again?"}
-        </Button>
+        {gameManager.state === "Finished" &&
+          (gameManager.gameType === "PrivateGame" ? (
+            user.isCreator && (
+              <Button
+                variant={"outline"}
+                onClick={() => performAction({ action: "rematch" })}
+                colorScheme={"twitter"}
+              >
+                {"Rematch"}
+              </Button>
+            )
+          ) : (
+            <Button
+              variant={"outline"}
+              onClick={playAgain}
+              colorScheme={"twitter"}
+            >
+              {"Play Again?"}
+            </Button>
+          ))}
       </VStack>
       <VStack mt={"8"} width={{ base: "sm", md: "2xl" }}>
         <Box fontSize={"3xl"} fontWeight={"semibold"}>
@@ -731,10 +771,13 @@ const Home = () => {
   const { user } = useAuth();
   const location = useLocation();
   const gameId = location.pathname.split("/")[2];
+  const spectate =
+    new URLSearchParams(location.search).get("spectate") === "true";
   const [gameManager, performAction] = useGameManager(
     user.token,
     gameId,
-    user.name
+    user.name,
+    spectate
   );
 
   return (
@@ -743,8 +786,12 @@ const Home = () => {
         <Flex direction={"column"} align={"center"}>
           {gameManager.state === "Lobby" ? (
             <Lobby gameManager={gameManager} performAction={performAction} />
-          ) : gameManager.state === "Finished" ? (
-            <Finished gameManager={gameManager} />
+          ) : gameManager.state === "Finished" ||
+            gameManager.state === "BetweenRounds" ? (
+            <Finished
+              gameManager={gameManager}
+              performAction={performAction}
+            />
           ) : (
             <Game gameManager={gameManager} performAction={performAction} />
           )}
@@ -6,7 +6,7 @@ import { useEffect, useState } from "react";
 import { Link, useNavigate } from "react-router-dom";
 import { Keyboard, KeyboardData } from "./Keyboards";
 import { ArrowUpIcon, EditIcon } from "@chakra-ui/icons";
-import { createGame, joinGame, joinRandomGame } from "./api";
+import { createGame, joinGame, joinRandomGame, joinRankedGame } from "./api";
 
 import {
   Button,
@@ -122,6 +122,11 @@ const JoinGame = () => {
     navigate(`/game/${id}`);
   };
 
+  const joinRanked = async () => {
+    let id = await joinRankedGame(user.token);
+    navigate(`/game/${id}`);
+  };
+
   return (
     <Box
       p={"7"}
@@ -138,6 +143,17 @@ const JoinGame = () => {
       <Button width={"full"} onClick={joinRandom} colorScheme={"blue"}>
         {"Join Public Game"}
       </Button>
+      {user.token !== "" && (
+        <Button
+          width={"full"}
+          mt={"2"}
+          onClick={joinRanked}
+          colorScheme={"blue"}
+          variant={"outline"}
+        >
+          {"Play Ranked"}
+        </Button>
+      )}
       <Box textAlign={"center"} my={"4"} color={"gray.800"}>
         {"OR"}
       </Box>
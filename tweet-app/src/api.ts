@@ -1,7 +1,8 @@
 import { server } from "./config";
 import { KeyboardData } from "./Keyboards";
+import { GameSettings, GhostSource, WordError } from "./logic";
 
-const createGame = async (token: string) => {
+const createGame = async (token: string, settings?: Partial<GameSettings>) => {
   const response = await fetch(`${server}/createGame`, {
     method: "POST",
     mode: "cors",
@@ -9,13 +10,45 @@ const createGame = async (token: string) => {
       Authorization: `Bearer ${token}`,
       "Content-Type": "application/json",
     }),
+    body: settings ? JSON.stringify(settings) : undefined,
   });
   const data: number = await response.json();
   return data;
 };
 
-const joinGame = async (code: number) => {
-  const response = await fetch(`${server}/joinGame?id=${code}`, {
+export interface GhostRequest {
+  source: GhostSource;
+  // For replay ghosts, the rematch, round and track of a game's replay
+  gameId?: string;
+  rematch?: number;
+  round?: number;
+  track?: number;
+}
+
+const createGhostGame = async (
+  token: string,
+  ghosts: GhostRequest[],
+  tweetId?: string,
+  typingMode?: string
+) => {
+  const response = await fetch(`${server}/createGhostGame`, {
+    method: "POST",
+    mode: "cors",
+    headers: new Headers({
+      Authorization: `Bearer ${token}`,
+      "Content-Type": "application/json",
+    }),
+    body: JSON.stringify({ tweetId, typingMode, ghosts }),
+  });
+  if (!response.ok) {
+    return null;
+  }
+  const data: number = await response.json();
+  return data;
+};
+
+const joinGame = async (code: number, spectate = false) => {
+  const response = await fetch(`${server}/joinGame?id=${code}&spectate=${spectate}`, {
     method: "GET",
     mode: "cors",
   });
@@ -40,7 +73,21 @@ const joinRandomGame = async (token: string) => {
   return data;
 };
 
-const login = async (name: string, email: string, picture: string) => {
+const joinRankedGame = async (token: string) => {
+  const response = await fetch(`${server}/joinRankedGame`, {
+    method: "POST",
+    mode: "cors",
+    headers: new Headers({
+      Authorization: `Bearer ${token}`,
+      "Content-Type": "application/json",
+    }),
+  });
+  const data: number = await response.json();
+  return data;
+};
+
+// Signs in with a Google ID token, which the server verifies
+const login = async (idToken: string) => {
   try {
     const response = await fetch(`${server}/signin`, {
       method: "POST",
@@ -48,11 +95,7 @@ const login = async (name: string, email: string, picture: string) => {
       headers: new Headers({
         "Content-Type": "application/json",
       }),
-      body: JSON.stringify({
-        name: name,
-        email: email,
-        picture: picture,
-      }),
+      body: JSON.stringify({ idToken: idToken }),
     });
     if (response.status == 200) {
       let data: string = await response.json();
@@ -65,6 +108,160 @@ const login = async (name: string, email: string, picture: string) => {
   }
 };
 
+export type LeaderboardBoard = "speed" | "points" | "winRate" | "rating";
+export type LeaderboardWindow = "all" | "month" | "week";
+
+export interface LeaderboardEntry {
+  rank: number;
+  name: string;
+  score: number;
+}
+
+export interface Leaderboard {
+  board: LeaderboardBoard;
+  window: LeaderboardWindow;
+  page: number;
+  pageSize: number;
+  total: number;
+  entries: LeaderboardEntry[];
+  player: LeaderboardEntry | null;
+}
+
+export interface MatchParticipant {
+  name: string;
+  speed: number;
+  rawSpeed: number;
+  accuracy: number;
+  consistency: number;
+  progress: number;
+  finished: boolean;
+  guess: string;
+  guessedAuthor: boolean;
+  placement: number;
+  points: number;
+  ratingChange?: number;
+  wordErrors?: WordError[];
+  // Voided results keep no speeds and score nothing
+  voided?: boolean;
+  isUser: boolean;
+}
+
+export interface Match {
+  id: string;
+  gameId: string;
+  rematch: number;
+  type: string;
+  ranked: boolean;
+  round: number;
+  rounds: number;
+  tweetId: string;
+  tweet: string;
+  author: string;
+  authorHandle: string;
+  settings: unknown;
+  startTime: string;
+  endTime: string;
+  participants: MatchParticipant[];
+}
+
+export interface Matches {
+  page: number;
+  pageSize: number;
+  total: number;
+  matches: Match[];
+}
+
+export interface ReplayTrack {
+  name: string;
+  placement: number;
+  finished: boolean;
+  typingTime: number;
+  // Milliseconds since the keystroke before, the first since the round started
+  times: number[];
+  keys: string[];
+  // c for a right key, e for a wrong one, r for one the server rejected
+  marks: string;
+}
+
+export interface Replay {
+  matchId: string;
+  gameId: string;
+  rematch: number;
+  round: number;
+  tweet: string;
+  tweetId: string;
+  typingMode: string;
+  startTime: string;
+  duration: number;
+  tracks: ReplayTrack[];
+}
+
+const getLeaderboard = async (
+  token: string,
+  board: LeaderboardBoard,
+  window: LeaderboardWindow,
+  page = 1
+) => {
+  const params = new URLSearchParams({ board, window, page: `${page}` });
+  const response = await fetch(`${server}/leaderboard?${params}`, {
+    method: "GET",
+    mode: "cors",
+    headers: new Headers({
+      Authorization: `Bearer ${token}`,
+      "Content-Type": "application/json",
+    }),
+  });
+  const data: Leaderboard = await response.json();
+  return data;
+};
+
+const getMatches = async (token: string, page = 1) => {
+  const params = new URLSearchParams({ page: `${page}` });
+  const response = await fetch(`${server}/matches?${params}`, {
+    method: "GET",
+    mode: "cors",
+    headers: new Headers({
+      Authorization: `Bearer ${token}`,
+      "Content-Type": "application/json",
+    }),
+  });
+  const data: Matches = await response.json();
+  return data;
+};
+
+const getMatch = async (token: string, id: string) => {
+  const response = await fetch(`${server}/matches/${id}`, {
+    method: "GET",
+    mode: "cors",
+    headers: new Headers({
+      Authorization: `Bearer ${token}`,
+      "Content-Type": "application/json",
+    }),
+  });
+  if (!response.ok) {
+    return null;
+  }
+  const data: Match = await response.json();
+  return data;
+};
+
+const getReplays = async (token: string, gameId: string, rematch = 0) => {
+  const params = new URLSearchParams({ rematch: `${rematch}` });
+  const response = await fetch(`${server}/replays/${gameId}?${params}`, {
+    method: "GET",
+    mode: "cors",
+    headers: new Headers({
+      Authorization: `Bearer ${token}`,
+      "Content-Type": "application/json",
+    }),
+  });
+  if (!response.ok) {
+    return [];
+  }
+  const data: Replay[] = await response.json();
+  return data;
+};
+
 const getPlayerStats = async (token: string) => {
   const response = await fetch(`${server}/playerStats`, {
     method: "GET",
@@ -156,6 +353,12 @@ export {
   joinGame,
   createGame,
   joinRandomGame,
+  joinRankedGame,
+  getLeaderboard,
+  getMatches,
+  getMatch,
+  getReplays,
+  createGhostGame,
   getPlayerStats,
   getAllKeyboards,
   changePlayerName,
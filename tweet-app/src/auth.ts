@@ -2,7 +2,7 @@ import words from "./words";
 import useStorage from "./hooks";
 import jwt_decode from "jwt-decode";
 import { KeyboardData } from "./Keyboards";
-import { useContext, createContext } from "react";
+import { useContext, createContext, useEffect } from "react";
 import {
   login,
   getPlayerStats,
@@ -19,6 +19,18 @@ export interface Stats {
   MatchesWon: number;
   AvgAccuracy: number;
   MatchesPlayed: number;
+  Rating?: number;
+  RatingDeviation?: number;
+  RatingHistory?: RatingChange[];
+}
+
+export interface RatingChange {
+  gameId: string;
+  rating: number;
+  deviation: number;
+  change: number;
+  placement: number;
+  time: number;
 }
 
 export interface User {
@@ -84,7 +96,7 @@ const useProviderAuth = () => {
     let email = object.email;
     let name = object.name;
     let picture = object.picture;
-    let token = await login(name, email, picture);
+    let token = await login(response.credential);
     if (token === "") {
       setUser(userGuest);
     } else {
@@ -101,6 +113,18 @@ const useProviderAuth = () => {
     setUser(userGuest);
   };
 
+  // The server stops taking a token once it expires, and tokens from before
+  // sessions expired have no expiry at all, so both sign out
+  useEffect(() => {
+    if (user.token === "") {
+      return;
+    }
+    let { exp }: { exp?: number } = jwt_decode(user.token);
+    if (!exp || exp * 1000 <= Date.now()) {
+      setUser(userGuest);
+    }
+  }, [user.token]);
+
   const changeName = async (newName: string) => {
     if (user.token === "") {
       setUser({ ...user, name: newName });
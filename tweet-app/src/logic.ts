@@ -4,10 +4,17 @@ import { useState, useEffect } from "react";
 /* Actions */
 export type Action =
   | RegisterPlayerAction
+  | SpectateAction
   | IsPlayerCreatorAction
   | StartCountdownAction
   | PlayerMoveAction
   | PlayerGuessAction
+  | UpdateSettingsAction
+  | RematchAction
+  | ReadyAction
+  | KickPlayerAction
+  | TransferHostAction
+  | LockRoomAction
   | PingAction;
 
 interface RegisterPlayerAction {
@@ -15,6 +22,11 @@ interface RegisterPlayerAction {
   data: { name: string };
 }
 
+interface SpectateAction {
+  action: "spectate";
+  data: { name: string };
+}
+
 interface IsPlayerCreatorAction {
   action: "isPlayerCreator";
 }
@@ -33,6 +45,35 @@ interface PlayerGuessAction {
   data: { guess: string };
 }
 
+interface UpdateSettingsAction {
+  action: "updateSettings";
+  data: { settings: GameSettings };
+}
+
+interface RematchAction {
+  action: "rematch";
+}
+
+interface ReadyAction {
+  action: "ready";
+  data: { ready: boolean };
+}
+
+interface KickPlayerAction {
+  action: "kickPlayer";
+  data: { playerId: string };
+}
+
+interface TransferHostAction {
+  action: "transferHost";
+  data: { playerId: string };
+}
+
+interface LockRoomAction {
+  action: "lockRoom";
+  data: { locked: boolean };
+}
+
 interface PingAction {
   action: "ping";
 }
@@ -40,10 +81,18 @@ interface PingAction {
 /* Messages */
 export type Message =
   | SendGameTypeMessage
+  | SendSettingsMessage
   | SendActivePlayersMessage
+  | SendSpectatorsMessage
+  | SendGameSnapshotMessage
+  | PlayerUpdatesMessage
   | StartCountdownMessage
   | StartGameMessage
   | StartFinishMessage
+  | RoundFinishMessage
+  | RematchMessage
+  | ReadyCheckMessage
+  | RoomLockedMessage
   | PongMessage;
 
 interface SendGameTypeMessage {
@@ -51,11 +100,37 @@ interface SendGameTypeMessage {
   data: GameType;
 }
 
+interface SendSettingsMessage {
+  action: "sendSettings";
+  data: GameSettings;
+}
+
 interface SendActivePlayersMessage {
   action: "sendActivePlayers";
   data: Player[];
 }
 
+interface SendSpectatorsMessage {
+  action: "sendSpectators";
+  data: Spectator[];
+}
+
+interface SendGameSnapshotMessage {
+  action: "sendGameSnapshot";
+  data: {
+    state: GameState;
+    tweet?: string;
+    graphemes?: string[];
+    authorChoices?: string[];
+    spectating: boolean;
+  };
+}
+
+interface PlayerUpdatesMessage {
+  action: "playerUpdates";
+  data: PlayerUpdate[];
+}
+
 interface StartCountdownMessage {
   action: "startCountdown";
   data: { state: GameState; clock: number };
@@ -63,12 +138,83 @@ interface StartCountdownMessage {
 
 interface StartGameMessage {
   action: "startGame";
-  data: { state: GameState; tweet: string; authorChoices: string[] };
+  data: {
+    state: GameState;
+    tweet: string;
+    graphemes: string[];
+    authorChoices: string[];
+  };
+}
+
+export interface PlayerResult {
+  id: string;
+  grossWpm: number;
+  netWpm: number;
+  accuracy: number;
+  samples: number[];
+  consistency: number;
+  correctedErrors: number;
+  uncorrectedErrors: number;
+  wordErrors: WordError[];
+  // Set when the result looked automated
+  voided?: boolean;
+  underReview?: boolean;
+}
+
+// Mistakes in one word of the tweet, counting words from 0
+export interface WordError {
+  word: number;
+  text: string;
+  errors: number;
+}
+
+export interface MatchStanding {
+  id: string;
+  name: string;
+  points: number;
+  roundsWon: number;
+  placement: number;
 }
 
 interface StartFinishMessage {
   action: "startFinish";
-  data: { state: GameState; author: string; authorHandle: string };
+  data: {
+    state: GameState;
+    author: string;
+    authorHandle: string;
+    round: number;
+    rounds: number;
+    results: PlayerResult[];
+    scoreboard: MatchStanding[];
+  };
+}
+
+interface RoundFinishMessage {
+  action: "roundFinish";
+  data: {
+    state: GameState;
+    author: string;
+    authorHandle: string;
+    round: number;
+    rounds: number;
+    clock: number;
+    results: PlayerResult[];
+    scoreboard: MatchStanding[];
+  };
+}
+
+interface RematchMessage {
+  action: "rematch";
+  data: { state: GameState };
+}
+
+interface ReadyCheckMessage {
+  action: "readyCheck";
+}
+
+interface RoomLockedMessage {
+  action: "roomLocked";
+  data: { locked: boolean };
 }
 
 interface PongMessage {
@@ -78,9 +224,16 @@ interface PongMessage {
 /* Game Logic */
 type PlayerState = "Typing" | "Guessing" | "Completed";
 
-type GameState = "Lobby" | "Countdown" | "Started" | "Finished";
+type GameState =
+  | "Lobby"
+  | "Countdown"
+  | "Started"
+  | "BetweenRounds"
+  | "Finished";
+
+type GameType = "PublicGame" | "PrivateGame" | "GhostGame";
 
-type GameType = "PublicGame" | "PrivateGame";
+export type GhostSource = "personalBest" | "top" | "replay";
 
 type PerformAction = (action: Action) => void;
 
@@ -97,27 +250,62 @@ export interface GameResult {
   gameStartTime: string;
 }
 
+export interface GameSettings {
+  maxPlayers: number;
+  timeLimit: number;
+  guessTimeLimit: number;
+  countdown: number;
+  authorChoices: number;
+  minTweetLength: number;
+  maxTweetLength: number;
+  categories: string[];
+  guessingEnabled: boolean;
+  typingMode: "StrictTyping" | "RealTyping";
+  scoringRule: string;
+  rounds: number;
+  text?: TextOptions;
+}
+
+export interface TextOptions {
+  smartQuotes: boolean;
+  foldAccents: boolean;
+  skipEmoji: boolean;
+}
+
 export interface Tweet {
   tweet: string;
+  graphemes: string[];
   author: string;
   authorHandle: string;
   authorChoices: string[];
 }
 
 export interface Player {
+  id: string;
   name: string;
   speed: number;
   points: number;
   isUser: boolean;
   placement: number;
   isCreator: boolean;
+  ready: boolean;
   state: PlayerState;
   keyboardLink: string;
   correctAnswers: number;
   incorrectAnswers: number;
   currentLetterIdx: number;
+  // Set for ghosts to where their run came from
+  ghost?: GhostSource;
 }
 
+export interface Spectator {
+  id: string;
+  name: string;
+  isUser: boolean;
+}
+
+type PlayerUpdate = Partial<Player> & { id: string };
+
 export interface GameManager {
   tweet: Tweet;
   gameId: string;
@@ -127,16 +315,29 @@ export interface GameManager {
   timeLimit: number;
   gameType: GameType;
   countdownTimer: number;
+  settings?: GameSettings;
+  scoreboard?: MatchStanding[];
+  readyCheck: boolean;
+  locked: boolean;
+  spectators: Spectator[];
+  spectating: boolean;
 }
 
 export function useGameManager(
   token: string,
   gameId: string,
-  playerName: string
+  playerName: string,
+  spectate = false
 ): [GameManager, PerformAction] {
   const [performAction, setPerformAction] = useState<PerformAction>(() => {});
   const [gameManager, setGameManager] = useState<GameManager>({
-    tweet: { tweet: "", author: "", authorHandle: "", authorChoices: [] },
+    tweet: {
+      tweet: "",
+      graphemes: [],
+      author: "",
+      authorHandle: "",
+      authorChoices: [],
+    },
     players: [],
     state: "Lobby",
     gameId: gameId,
@@ -144,6 +345,10 @@ export function useGameManager(
     timeLimit: 45,
     gameType: "PrivateGame",
     countdownTimer: 0,
+    readyCheck: false,
+    locked: false,
+    spectators: [],
+    spectating: spectate,
   });
 
   const PING_RATE = 30000;
@@ -157,7 +362,7 @@ export function useGameManager(
       });
       soc.send(
         JSON.stringify({
-          action: "registerPlayer",
+          action: spectate ? "spectate" : "registerPlayer",
           data: { name: gameManager.playerName },
         })
       );
@@ -184,15 +389,64 @@ export function useGameManager(
             gameType: message.data,
           }));
           break;
+        case "sendSettings":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            settings: message.data,
+            timeLimit: message.data.timeLimit,
+            readyCheck: false,
+          }));
+          break;
         case "sendActivePlayers":
           setGameManager((gameManager) => ({
             ...gameManager,
             players: message.data,
           }));
           break;
+        case "sendSpectators":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            spectators: message.data,
+          }));
+          break;
+        case "sendGameSnapshot":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            state: message.data.state,
+            spectating: message.data.spectating,
+            tweet: {
+              ...gameManager.tweet,
+              tweet: message.data.tweet ?? "",
+              graphemes: message.data.graphemes ?? [],
+              authorChoices: message.data.authorChoices ?? [],
+            },
+          }));
+          break;
+        case "playerUpdates":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            players: gameManager.players.map((player) => {
+              const update = message.data.find((u) => u.id === player.id);
+              return update ? { ...player, ...update } : player;
+            }),
+          }));
+          break;
+        case "readyCheck":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            readyCheck: true,
+          }));
+          break;
+        case "roomLocked":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            locked: message.data.locked,
+          }));
+          break;
         case "startCountdown":
           setGameManager((gameManager) => ({
             ...gameManager,
+            readyCheck: false,
             state: message.data.state,
             countdownTimer: message.data.clock,
           }));
@@ -203,18 +457,29 @@ export function useGameManager(
             state: message.data.state,
             tweet: {
               tweet: message.data.tweet,
+              graphemes: message.data.graphemes,
               author: gameManager.tweet.author,
               authorHandle: gameManager.tweet.authorHandle,
               authorChoices: message.data.authorChoices,
             },
           }));
           break;
+        case "rematch":
+          setGameManager((gameManager) => ({
+            ...gameManager,
+            state: message.data.state,
+            scoreboard: undefined,
+          }));
+          break;
         case "startFinish":
+        case "roundFinish":
           setGameManager((gameManager) => ({
             ...gameManager,
             state: message.data.state,
+            scoreboard: message.data.scoreboard,
             tweet: {
               tweet: gameManager.tweet.tweet,
+              graphemes: gameManager.tweet.graphemes,
               author: message.data.author,
               authorHandle: message.data.authorHandle,
               authorChoices: gameManager.tweet.authorChoices,